@@ -2,19 +2,31 @@ package dolphin
 
 import (
 	"context"
+	"errors"
 	"io"
 	"log"
+	"log/slog"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
 	"sync"
+	"syscall"
+	"time"
 )
 
 // App is the dolphin web server engine.
 type App struct {
 	certFile *string
 
+	cookieKeys *cookieKeys
+
+	errorHandler ErrorHandler
+
 	handlers HandlerChain
 
+	hooks appHooks
+
 	keyFile *string
 
 	logger *log.Logger
@@ -23,19 +35,28 @@ type App struct {
 
 	port int
 
+	problemDetails bool
+
 	reqPool *sync.Pool
 
 	resPool *sync.Pool
 
 	server *http.Server
+
+	shutdownHookTimeout time.Duration
+
+	slogger *slog.Logger
+
+	startTimeout time.Duration
+
+	unixSocketMode os.FileMode
 }
 
-// Run starts the app and listens on the given port.
+// Run starts the app and listens on the given port. The OnStart hooks are called before
+// listening, and the OnReady hooks are called after the listener is bound. The errors are logged
+// by the app logger, use Listen to get the errors.
 func (app *App) Run() {
-	app.initServer()
-
-	err := app.server.ListenAndServe()
-	if err != nil && err != http.ErrServerClosed {
+	if err := app.Listen(""); err != nil {
 		app.log("Failed to run server: %v\n", err)
 	}
 }
@@ -49,10 +70,13 @@ func (app *App) RunTLS() error {
 		return ErrNoTLSKey
 	}
 
-	app.initServer()
-
-	err := app.server.ListenAndServeTLS(*app.certFile, *app.keyFile)
+	ln, err := app.start(app.listenPort)
+	if err != nil {
+		app.log("Failed to run server: %v\n", err)
+		return err
+	}
 
+	err = app.server.ServeTLS(ln, *app.certFile, *app.keyFile)
 	if err != nil && err != http.ErrServerClosed {
 		app.log("Failed to run server: %v\n", err)
 		return err
@@ -61,13 +85,157 @@ func (app *App) RunTLS() error {
 	return nil
 }
 
-// Shutdown tries to close active connections and stops the server.
+// RunWithGracefulShutdown starts the app like Run, and shuts it down gracefully when the process
+// receives one of the signals, default SIGINT and SIGTERM. The server stops accepting new
+// connections, waits up to the timeout for the in-flight requests, then calls the OnShutdown
+// hooks within Config.ShutdownHookTimeout. It returns the combined errors of the server and the
+// hooks, or nil if the app is shut down cleanly. The timeout is unlimited if it's not positive.
+//
+//	if err := app.RunWithGracefulShutdown(30 * time.Second); err != nil {
+//		log.Fatal(err)
+//	}
+func (app *App) RunWithGracefulShutdown(timeout time.Duration, signals ...os.Signal) error {
+	if len(signals) == 0 {
+		signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, signals...)
+	defer signal.Stop(stop)
+
+	ln, err := app.start(app.listenPort)
+	if err != nil {
+		return err
+	}
+
+	return app.serveUntil(func() error { return app.server.Serve(ln) }, stop, timeout)
+}
+
+// start calls the OnStart hooks, creates the listener by the listen function, and calls the
+// OnReady hooks with the listener address. If any step fails, the listener is closed and the
+// OnShutdown hooks are called to release the resources that acquired by the previous hooks.
+func (app *App) start(listen func() (net.Listener, error)) (net.Listener, error) {
+	app.initServer()
+
+	ctx, cancel := context.WithTimeout(context.Background(), app.startTimeout)
+	defer cancel()
+
+	mark := app.shutdownHookMark()
+
+	err := app.runStartHooks(ctx)
+	if err != nil {
+		return nil, app.abortStart(err, mark)
+	}
+
+	ln, err := listen()
+	if err != nil {
+		return nil, app.abortStart(err, mark)
+	}
+	app.log("Server running at %s.\n", ln.Addr())
+
+	if err := app.runReadyHooks(ctx, ln.Addr()); err != nil {
+		ln.Close()
+		return nil, app.abortStart(err, mark)
+	}
+
+	return ln, nil
+}
+
+// listenPort listens on the port of the app.
+func (app *App) listenPort() (net.Listener, error) {
+	return net.Listen("tcp", resolveListenAddr(&app.port))
+}
+
+// serve accepts the connections on the listener until the server is shut down, it returns nil
+// if the server is closed by Shutdown.
+func (app *App) serve(ln net.Listener) error {
+	err := app.server.Serve(ln)
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+
+	return err
+}
+
+// abortStart calls the OnShutdown hooks that registered during the startup after it failed, and
+// returns the combined errors.
+func (app *App) abortStart(err error, mark int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), app.shutdownHookTimeout)
+	defer cancel()
+
+	if hookErr := app.runShutdownHooks(ctx, mark); hookErr != nil {
+		return errors.Join(err, hookErr)
+	}
+
+	return err
+}
+
+// serveUntil runs the serve function until it fails or a signal is received from the stop
+// channel, and shuts down the app gracefully with the timeout after the signal.
+func (app *App) serveUntil(serve func() error, stop <-chan os.Signal, timeout time.Duration) error {
+	served := make(chan error, 1)
+	go func() {
+		served <- serve()
+	}()
+
+	select {
+	case err := <-served:
+		if errors.Is(err, http.ErrServerClosed) {
+			// The app has been shut down by Shutdown.
+			return nil
+		}
+		return err
+	case sig := <-stop:
+		app.log("Received %v, shutting down server.\n", sig)
+	}
+
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	err := app.Shutdown(ctx)
+	if serveErr := <-served; !errors.Is(serveErr, http.ErrServerClosed) {
+		err = errors.Join(serveErr, err)
+	}
+
+	return err
+}
+
+// Shutdown stops the server gracefully, it stops accepting new connections and waits for the
+// in-flight requests until the context is done, the remaining connections are closed if the
+// context is done first. Then it calls the OnShutdown hooks in the reverse order of registration,
+// and returns the combined errors of the server and the hooks.
+//
+// The hooks have their own budget of Config.ShutdownHookTimeout that starts after the requests
+// are drained, so they still run if draining uses up the context. The values of the context are
+// kept for the hooks, but its cancellation is not. A hook that doesn't return within the budget
+// is abandoned, and its error is context.DeadlineExceeded.
 func (app *App) Shutdown(ctx ...context.Context) error {
 	if len(ctx) == 0 {
 		ctx = append(ctx, context.Background())
 	}
 
-	return app.server.Shutdown(ctx[0])
+	errs := make([]error, 0, 2)
+	if app.server != nil {
+		if err := app.server.Shutdown(ctx[0]); err != nil {
+			errs = append(errs, err)
+			if err := app.server.Close(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	hookCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx[0]), app.shutdownHookTimeout)
+	defer cancel()
+
+	if err := app.runShutdownHooks(hookCtx, 0); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
 }
 
 // ServeHTTP implements the http.Handler interface.
@@ -115,9 +283,34 @@ func (app *App) Logger() *log.Logger {
 	return app.logger
 }
 
-// SetLogger sets the app logger.
+// SetLogger sets the app logger, the structured logger is also replaced with a text logger that
+// writes to the logger's writer.
 func (app *App) SetLogger(logger *log.Logger) {
 	app.logger = logger
+	app.slogger = nil
+	if logger != nil {
+		app.slogger = slog.New(slog.NewTextHandler(logger.Writer(), nil))
+	}
+}
+
+// StructuredLogger returns the app structured logger, or slog.Default() if the logger is not
+// set.
+func (app *App) StructuredLogger() *slog.Logger {
+	if app.slogger != nil {
+		return app.slogger
+	}
+
+	return slog.Default()
+}
+
+// SetStructuredLogger sets the app structured logger, the app logger is also replaced with a
+// logger that writes the messages to the structured logger at the info level.
+func (app *App) SetStructuredLogger(logger *slog.Logger) {
+	app.slogger = logger
+	app.logger = nil
+	if logger != nil {
+		app.logger = slog.NewLogLogger(logger.Handler(), slog.LevelInfo)
+	}
 }
 
 // LoggerWriter returns the app logger's writer, or os.Stderr if the app logger is not set.
@@ -129,12 +322,8 @@ func (app *App) LoggerWriter() io.Writer {
 	return os.Stderr
 }
 
-// initServer gets listenning port and initialize HTTP server.
+// initServer initializes the HTTP server.
 func (app *App) initServer() {
-	addr := resolveListenAddr(&app.port)
-	app.log("Server running at %s.\n", addr)
-
-	app.server.Addr = addr
 	app.server.Handler = app
 }
 
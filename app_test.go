@@ -0,0 +1,348 @@
+package dolphin
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// testListen returns a listener on a random local port.
+func testListen(t *testing.T) net.Listener {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen expect no error, actual %v", err)
+	}
+
+	return ln
+}
+
+func TestAppShutdownBeforeRun(t *testing.T) {
+	var called bool
+
+	app := New(nil)
+	app.OnShutdown(func(ctx context.Context) error {
+		called = true
+		return nil
+	})
+
+	if err := app.Shutdown(); err != nil {
+		t.Errorf("Shutdown expect no error, actual %v", err)
+	}
+	if !called {
+		t.Error("Shutdown hook expect called, actual not")
+	}
+
+	if err := (&App{}).Shutdown(); err != nil {
+		t.Errorf("Shutdown zero app expect no error, actual %v", err)
+	}
+}
+
+func TestAppGracefulShutdown(t *testing.T) {
+	ln := testListen(t)
+	addr := ln.Addr().String()
+
+	started := make(chan struct{})
+	app := New(nil)
+	app.Use(func(ctx *Context) {
+		close(started)
+		time.Sleep(100 * time.Millisecond)
+		ctx.String("done")
+	})
+
+	order := make([]int, 0, 3)
+	hookErr := errors.New("close database")
+	app.OnShutdown(func(ctx context.Context) error {
+		order = append(order, 1)
+		return nil
+	}, func(ctx context.Context) error {
+		order = append(order, 2)
+		return hookErr
+	})
+	app.OnShutdown(func(ctx context.Context) error {
+		if _, ok := ctx.Deadline(); !ok {
+			t.Error("Shutdown hook context expect deadline, actual none")
+		}
+		order = append(order, 3)
+		return nil
+	})
+
+	stop := make(chan os.Signal, 1)
+	result := make(chan error, 1)
+	app.server.Handler = app
+	go func() {
+		result <- app.serveUntil(func() error { return app.server.Serve(ln) }, stop, time.Second)
+	}()
+
+	response := make(chan string, 1)
+	go func() {
+		resp, err := http.Get("http://" + addr)
+		if err != nil {
+			response <- err.Error()
+			return
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		response <- string(body)
+	}()
+
+	<-started
+	stop <- syscall.SIGTERM
+
+	if body := <-response; body != "done" {
+		t.Errorf("In-flight request expect \"done\", actual %q", body)
+	}
+
+	err := <-result
+	if !errors.Is(err, hookErr) || !strings.Contains(err.Error(), "shutdown hook #1") {
+		t.Errorf("Shutdown expect hook error, actual %v", err)
+	}
+	if len(order) != 3 || order[0] != 3 || order[1] != 2 || order[2] != 1 {
+		t.Errorf("Shutdown hooks order expect [3 2 1], actual %v", order)
+	}
+
+	if _, err := net.DialTimeout("tcp", addr, 100*time.Millisecond); err == nil {
+		t.Error("Dial after shutdown expect error, actual nil")
+	}
+}
+
+func TestAppShutdownHookTimeout(t *testing.T) {
+	app := New(&Config{ShutdownHookTimeout: 50 * time.Millisecond})
+	app.OnShutdown(func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	})
+
+	start := time.Now()
+	err := app.Shutdown()
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown expect deadline exceeded, actual %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Shutdown expect returning at the deadline, actual %v", elapsed)
+	}
+}
+
+func TestAppShutdownHooksAfterDrainTimeout(t *testing.T) {
+	ln := testListen(t)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	app := New(nil)
+	app.Use(func(ctx *Context) {
+		close(started)
+		<-release
+	})
+	defer close(release)
+
+	var hookErr error
+	flushed := false
+	app.OnShutdown(func(ctx context.Context) error {
+		hookErr = ctx.Err()
+		time.Sleep(50 * time.Millisecond)
+		flushed = true
+		return nil
+	})
+
+	app.server.Handler = app
+	go app.server.Serve(ln)
+	go http.Get("http://" + ln.Addr().String())
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err := app.Shutdown(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown expect drain deadline exceeded, actual %v", err)
+	}
+	if hookErr != nil {
+		t.Errorf("Shutdown hook context expect not done, actual %v", hookErr)
+	}
+	if !flushed {
+		t.Error("Shutdown hook expect finished, actual abandoned")
+	}
+}
+
+func TestAppServeError(t *testing.T) {
+	serveErr := errors.New("address already in use")
+
+	app := New(nil)
+	err := app.serveUntil(func() error { return serveErr }, make(chan os.Signal), time.Second)
+	if err != serveErr {
+		t.Errorf("serveUntil expect %v, actual %v", serveErr, err)
+	}
+}
+
+// testFreePort returns a free local port.
+func testFreePort(t *testing.T) int {
+	ln := testListen(t)
+	defer ln.Close()
+
+	return ln.Addr().(*net.TCPAddr).Port
+}
+
+func TestAppLifecycleHooks(t *testing.T) {
+	port := testFreePort(t)
+	app := New(&Config{Port: port})
+
+	events := make([]string, 0)
+	app.OnStart(func(ctx context.Context) error {
+		events = append(events, "start 1")
+		return nil
+	}, func(ctx context.Context) error {
+		events = append(events, "start 2")
+		return nil
+	})
+	app.OnReady(func(ctx context.Context, addr net.Addr) error {
+		events = append(events, "ready "+strconv.Itoa(addr.(*net.TCPAddr).Port))
+		return nil
+	})
+	app.OnShutdown(func(ctx context.Context) error {
+		events = append(events, "shutdown")
+		return nil
+	})
+
+	ln, err := app.start(app.listenPort)
+	if err != nil {
+		t.Fatalf("start expect no error, actual %v", err)
+	}
+	defer ln.Close()
+
+	expected := []string{"start 1", "start 2", "ready " + strconv.Itoa(port)}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("Hooks expect %v, actual %v", expected, events)
+	}
+}
+
+func TestAppStartHookError(t *testing.T) {
+	hookErr := errors.New("database unavailable")
+
+	cases := []struct {
+		name     string
+		setup    func(app *App, events *[]string)
+		expected []string
+	}{
+		{
+			name: "Start",
+			setup: func(app *App, events *[]string) {
+				app.OnStart(func(ctx context.Context) error {
+					app.OnShutdown(func(ctx context.Context) error {
+						*events = append(*events, "release failed")
+						return nil
+					})
+					return hookErr
+				})
+			},
+			expected: []string{"release started"},
+		},
+		{
+			name: "Ready",
+			setup: func(app *App, events *[]string) {
+				app.OnReady(func(ctx context.Context, addr net.Addr) error {
+					return hookErr
+				})
+			},
+			expected: []string{"ready", "release started"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			app := New(&Config{Port: testFreePort(t)})
+
+			events := make([]string, 0)
+			app.OnShutdown(func(ctx context.Context) error {
+				events = append(events, "shutdown")
+				return nil
+			})
+			app.OnStart(func(ctx context.Context) error {
+				app.OnShutdown(func(ctx context.Context) error {
+					events = append(events, "release started")
+					return nil
+				})
+				return nil
+			})
+			app.OnReady(func(ctx context.Context, addr net.Addr) error {
+				events = append(events, "ready")
+				return nil
+			})
+			c.setup(app, &events)
+			app.OnReady(func(ctx context.Context, addr net.Addr) error {
+				events = append(events, "unexpected")
+				return nil
+			})
+
+			ln, err := app.start(app.listenPort)
+			if ln != nil {
+				ln.Close()
+			}
+			if !errors.Is(err, hookErr) {
+				t.Errorf("start expect hook error, actual %v", err)
+			}
+			if !reflect.DeepEqual(events, c.expected) {
+				t.Errorf("Hooks expect %v, actual %v", c.expected, events)
+			}
+
+			events = events[:0]
+			if err := app.Shutdown(); err != nil {
+				t.Errorf("Shutdown expect no error, actual %v", err)
+			}
+			if expected := []string{"shutdown"}; !reflect.DeepEqual(events, expected) {
+				t.Errorf("Shutdown hooks expect %v, actual %v", expected, events)
+			}
+		})
+	}
+}
+
+func TestAppStartHookTimeout(t *testing.T) {
+	app := New(&Config{Port: testFreePort(t), StartTimeout: 50 * time.Millisecond})
+	app.OnStart(func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	})
+
+	_, err := app.start(app.listenPort)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("start expect deadline exceeded, actual %v", err)
+	}
+}
+
+func TestAppOnRoute(t *testing.T) {
+	app := New(nil)
+
+	router := app.NewRouter()
+	router.GET("/users/:id", func(ctx *Context) {})
+
+	routes := make([]string, 0)
+	app.OnRoute(func(route Route) {
+		routes = append(routes, route.Method+" "+route.Path)
+	})
+	router.POST("users/", func(ctx *Context) {})
+
+	// The routers that created by the package-level NewRouter are not attached to the app.
+	unattached := NewRouter()
+	unattached.GET("/unknown", func(ctx *Context) {})
+	app.Use(unattached.Routes())
+
+	expected := []string{"GET /users/:id", "POST /users"}
+	if !reflect.DeepEqual(routes, expected) {
+		t.Errorf("Routes expect %v, actual %v", expected, routes)
+	}
+
+	defer func() {
+		if p := recover(); p == nil {
+			t.Errorf("Unattached router expect panic, actual no panic")
+		}
+	}()
+	testServe(app, httptest.NewRequest(http.MethodGet, "/unknown", nil))
+}
@@ -0,0 +1,286 @@
+package dolphin
+
+import (
+	"errors"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// conditionResult is the result of evaluating the request preconditions.
+type conditionResult int
+
+const (
+	// conditionNone indicates the request should be processed normally.
+	conditionNone conditionResult = iota
+	// conditionNotModified indicates the client's cached representation is still valid, and
+	// the server should respond with 304 (Not Modified).
+	conditionNotModified
+	// conditionFailed indicates a precondition is failed, and the server should respond with
+	// 412 (Precondition Failed).
+	conditionFailed
+)
+
+// httpRange specifies the byte range to be sent to the client.
+type httpRange struct {
+	start, length int64
+}
+
+// errNoOverlap is returned by parseRange if none of the ranges overlap the content.
+var errNoOverlap = errors.New("invalid range: failed to overlap")
+
+// SetETag sets the entity tag of the response, the tag will be quoted if it's not quoted. The
+// weak entity tag should be set with the "W/" prefix, like `W/"v1"`.
+func (ctx *Context) SetETag(etag string) {
+	if !strings.HasSuffix(etag, `"`) {
+		etag = `"` + etag + `"`
+	}
+
+	ctx.SetHeader("ETag", etag)
+}
+
+// SetLastModified sets the "Last-Modified" header of the response.
+func (ctx *Context) SetLastModified(modtime time.Time) {
+	ctx.SetHeader("Last-Modified", modtime.UTC().Format(http.TimeFormat))
+}
+
+// CheckPreconditions evaluates the conditional request headers ("If-Match", "If-None-Match",
+// "If-Modified-Since", and "If-Unmodified-Since") with the validators that set by SetETag and
+// SetLastModified. It discards the response body and sets the status code to 304 (Not
+// Modified) or 412 (Precondition Failed) and returns true if the request should not be
+// processed anymore.
+//
+//	ctx.SetETag(article.Version)
+//	if ctx.CheckPreconditions() {
+//		return
+//	}
+func (ctx *Context) CheckPreconditions() bool {
+	var modtime time.Time
+	if lastModified := ctx.Response.Header("Last-Modified"); lastModified != "" {
+		modtime, _ = http.ParseTime(lastModified)
+	}
+
+	switch checkPreconditions(ctx.Request.request, ctx.Response.Header("ETag"), modtime) {
+	case conditionNotModified:
+		ctx.Response.ResetBody()
+		ctx.Response.DelHeader("Content-Type")
+		ctx.Response.DelHeader("Content-Length")
+		ctx.SetStatusCode(http.StatusNotModified)
+		return true
+	case conditionFailed:
+		ctx.Response.ResetBody()
+		ctx.Response.DelHeader("Content-Length")
+		ctx.String("Precondition Failed", http.StatusPreconditionFailed)
+		return true
+	}
+
+	return false
+}
+
+// checkPreconditions evaluates the conditional request headers with the given validators in
+// the order that defined in RFC 9110 Section 13.2.2.
+func checkPreconditions(req *http.Request, etag string, modtime time.Time) conditionResult {
+	if im := req.Header.Get("If-Match"); im != "" {
+		if !matchETags(im, etag, false) {
+			return conditionFailed
+		}
+	} else if ius := req.Header.Get("If-Unmodified-Since"); ius != "" && !isZeroTime(modtime) {
+		if t, err := http.ParseTime(ius); err == nil && modtime.Truncate(time.Second).After(t) {
+			return conditionFailed
+		}
+	}
+
+	isSafe := req.Method == http.MethodGet || req.Method == http.MethodHead
+
+	if inm := req.Header.Get("If-None-Match"); inm != "" {
+		if matchETags(inm, etag, true) {
+			if isSafe {
+				return conditionNotModified
+			}
+			return conditionFailed
+		}
+	} else if ims := req.Header.Get("If-Modified-Since"); ims != "" && isSafe && !isZeroTime(modtime) {
+		if t, err := http.ParseTime(ims); err == nil && !modtime.Truncate(time.Second).After(t) {
+			return conditionNotModified
+		}
+	}
+
+	return conditionNone
+}
+
+// checkIfRange reports whether the range request should be processed by the "If-Range"
+// header, it returns true if the header is not set or matches the current validators.
+func checkIfRange(req *http.Request, etag string, modtime time.Time) bool {
+	ir := req.Header.Get("If-Range")
+	if ir == "" {
+		return true
+	}
+
+	if tag, _ := scanETag(ir); tag != "" {
+		return matchETag(tag, etag, false)
+	}
+
+	if isZeroTime(modtime) {
+		return false
+	}
+
+	t, err := http.ParseTime(ir)
+	if err != nil {
+		return false
+	}
+
+	return t.Unix() == modtime.Unix()
+}
+
+// matchETags reports whether the given entity tag matches one of the entity tags in the list,
+// the list can also be "*" to match any current representation.
+func matchETags(list, etag string, weak bool) bool {
+	if etag == "" {
+		return false
+	}
+
+	for {
+		list = textproto.TrimString(list)
+		if len(list) == 0 {
+			return false
+		}
+		if list[0] == ',' {
+			list = list[1:]
+			continue
+		}
+		if list[0] == '*' {
+			return true
+		}
+
+		tag, remain := scanETag(list)
+		if tag == "" {
+			return false
+		}
+		if matchETag(tag, etag, weak) {
+			return true
+		}
+
+		list = remain
+	}
+}
+
+// matchETag compares two entity tags with the weak or the strong comparison function.
+func matchETag(a, b string, weak bool) bool {
+	if weak {
+		return strings.TrimPrefix(a, "W/") == strings.TrimPrefix(b, "W/")
+	}
+
+	return a == b && a != "" && !strings.HasPrefix(a, "W/")
+}
+
+// scanETag scans the first entity tag from the string, and returns the tag and the rest
+// string. It returns an empty tag if the string does not start with a valid entity tag.
+func scanETag(s string) (etag string, remain string) {
+	s = textproto.TrimString(s)
+	start := 0
+	if strings.HasPrefix(s, "W/") {
+		start = 2
+	}
+	if len(s[start:]) < 2 || s[start] != '"' {
+		return "", ""
+	}
+
+	for i := start + 1; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '"':
+			return s[:i+1], s[i+1:]
+		case c == 0x21 || c >= 0x23 && c <= 0x7E || c >= 0x80:
+			// Valid entity tag character.
+		default:
+			return "", ""
+		}
+	}
+
+	return "", ""
+}
+
+// parseRange parses the "Range" header with the content size, it only supports the "bytes"
+// unit.
+func parseRange(s string, size int64) ([]httpRange, error) {
+	const prefix = "bytes="
+
+	if !strings.HasPrefix(s, prefix) {
+		return nil, errors.New("invalid range")
+	}
+
+	ranges := make([]httpRange, 0)
+	noOverlap := false
+
+	for _, ra := range strings.Split(s[len(prefix):], ",") {
+		ra = textproto.TrimString(ra)
+		if ra == "" {
+			continue
+		}
+
+		startStr, endStr, ok := strings.Cut(ra, "-")
+		if !ok {
+			return nil, errors.New("invalid range")
+		}
+		startStr, endStr = textproto.TrimString(startStr), textproto.TrimString(endStr)
+
+		var r httpRange
+		if startStr == "" {
+			// Suffix range, like "-500" means the last 500 bytes.
+			if endStr == "" || endStr[0] == '-' {
+				return nil, errors.New("invalid range")
+			}
+			n, err := strconv.ParseInt(endStr, 10, 64)
+			if err != nil {
+				return nil, errors.New("invalid range")
+			}
+			if n == 0 {
+				noOverlap = true
+				continue
+			}
+			if n > size {
+				n = size
+			}
+			r.start = size - n
+			r.length = size - r.start
+		} else {
+			start, err := strconv.ParseInt(startStr, 10, 64)
+			if err != nil || start < 0 {
+				return nil, errors.New("invalid range")
+			}
+			if start >= size {
+				noOverlap = true
+				continue
+			}
+			r.start = start
+
+			if endStr == "" {
+				r.length = size - start
+			} else {
+				end, err := strconv.ParseInt(endStr, 10, 64)
+				if err != nil || start > end {
+					return nil, errors.New("invalid range")
+				}
+				if end >= size {
+					end = size - 1
+				}
+				r.length = end - start + 1
+			}
+		}
+
+		ranges = append(ranges, r)
+	}
+
+	if noOverlap && len(ranges) == 0 {
+		return nil, errNoOverlap
+	}
+
+	return ranges, nil
+}
+
+// isZeroTime reports whether the time is the zero time or the Unix epoch.
+func isZeroTime(t time.Time) bool {
+	return t.IsZero() || t.Equal(time.Unix(0, 0))
+}
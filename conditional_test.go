@@ -0,0 +1,152 @@
+package dolphin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCheckPreconditions(t *testing.T) {
+	modtime := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	before := modtime.Add(-time.Hour).Format(http.TimeFormat)
+	after := modtime.Add(time.Hour).Format(http.TimeFormat)
+
+	cases := []struct {
+		method string
+		header string
+		value  string
+		code   int
+	}{
+		{http.MethodGet, "", "", http.StatusOK},
+		{http.MethodGet, "If-None-Match", `"v1"`, http.StatusNotModified},
+		{http.MethodGet, "If-None-Match", `W/"v1"`, http.StatusNotModified},
+		{http.MethodGet, "If-None-Match", `"v0", "v1"`, http.StatusNotModified},
+		{http.MethodGet, "If-None-Match", `"v2"`, http.StatusOK},
+		{http.MethodGet, "If-None-Match", "*", http.StatusNotModified},
+		{http.MethodPut, "If-None-Match", "*", http.StatusPreconditionFailed},
+		{http.MethodPut, "If-Match", `"v1"`, http.StatusOK},
+		{http.MethodPut, "If-Match", `W/"v1"`, http.StatusPreconditionFailed},
+		{http.MethodPut, "If-Match", `"v2"`, http.StatusPreconditionFailed},
+		{http.MethodGet, "If-Modified-Since", after, http.StatusNotModified},
+		{http.MethodGet, "If-Modified-Since", before, http.StatusOK},
+		{http.MethodPut, "If-Unmodified-Since", before, http.StatusPreconditionFailed},
+		{http.MethodPut, "If-Unmodified-Since", after, http.StatusOK},
+	}
+
+	for _, c := range cases {
+		req := httptest.NewRequest(c.method, "/", nil)
+		if c.header != "" {
+			req.Header.Set(c.header, c.value)
+		}
+
+		rec := testRequest(func(ctx *Context) {
+			ctx.SetETag("v1")
+			ctx.SetLastModified(modtime)
+			if ctx.CheckPreconditions() {
+				return
+			}
+
+			ctx.String("OK")
+		}, req)
+
+		if rec.Code != c.code {
+			t.Errorf("%s with %s: %s expect %d, actual %d", c.method, c.header, c.value, c.code, rec.Code)
+		}
+		if c.code == http.StatusNotModified && rec.Body.Len() != 0 {
+			t.Errorf("%s with %s: %s expect empty body, actual %q", c.method, c.header, c.value, rec.Body.String())
+		}
+	}
+}
+
+func TestParseRange(t *testing.T) {
+	cases := []struct {
+		header string
+		ranges []httpRange
+		err    bool
+	}{
+		{"bytes=0-9", []httpRange{{0, 10}}, false},
+		{"bytes=5-", []httpRange{{5, 95}}, false},
+		{"bytes=-10", []httpRange{{90, 10}}, false},
+		{"bytes=90-200", []httpRange{{90, 10}}, false},
+		{"bytes=0-1, 5-6", []httpRange{{0, 2}, {5, 2}}, false},
+		{"bytes=100-", nil, true},
+		{"bytes=5-1", nil, true},
+		{"items=0-1", nil, true},
+	}
+
+	for _, c := range cases {
+		ranges, err := parseRange(c.header, 100)
+		if (err != nil) != c.err {
+			t.Errorf("parseRange(%q) expect error %v, actual %v", c.header, c.err, err)
+			continue
+		}
+
+		if len(ranges) != len(c.ranges) {
+			t.Errorf("parseRange(%q) expect %v, actual %v", c.header, c.ranges, ranges)
+			continue
+		}
+		for i := range ranges {
+			if ranges[i] != c.ranges[i] {
+				t.Errorf("parseRange(%q) expect %v, actual %v", c.header, c.ranges, ranges)
+			}
+		}
+	}
+}
+
+func TestCheckIfRange(t *testing.T) {
+	modtime := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		value string
+		etag  string
+		ok    bool
+	}{
+		{"", `"v1"`, true},
+		{`"v1"`, `"v1"`, true},
+		{`"v2"`, `"v1"`, false},
+		{`W/"v1"`, `W/"v1"`, false},
+		{`"v1"`, `W/"v1"`, false},
+		{modtime.Format(http.TimeFormat), `"v1"`, true},
+		{modtime.Add(time.Hour).Format(http.TimeFormat), `"v1"`, false},
+		{"invalid", `"v1"`, false},
+	}
+
+	for _, c := range cases {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if c.value != "" {
+			req.Header.Set("If-Range", c.value)
+		}
+
+		if ok := checkIfRange(req, c.etag, modtime); ok != c.ok {
+			t.Errorf("If-Range %q with %s expect %v, actual %v", c.value, c.etag, c.ok, ok)
+		}
+	}
+}
+
+func TestIfRangeFallback(t *testing.T) {
+	app := testStaticApp()
+	modtime := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		value string
+		code  int
+		body  string
+	}{
+		{modtime.Format(http.TimeFormat), http.StatusPartialContent, "console"},
+		{modtime.Add(time.Hour).Format(http.TimeFormat), http.StatusOK, "console.log('dolphin');"},
+		// The entity tags of the static files are weak, so they never match the If-Range.
+		{`W/"v1"`, http.StatusOK, "console.log('dolphin');"},
+	}
+
+	for _, c := range cases {
+		req := httptest.NewRequest(http.MethodGet, "/assets/app.js", nil)
+		req.Header.Set("Range", "bytes=0-6")
+		req.Header.Set("If-Range", c.value)
+		rec := testServe(app, req)
+
+		if rec.Code != c.code || rec.Body.String() != c.body {
+			t.Errorf("If-Range %q expect %d %q, actual %d %q", c.value, c.code, c.body, rec.Code, rec.Body.String())
+		}
+	}
+}
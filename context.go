@@ -1,14 +1,28 @@
 package dolphin
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"encoding/xml"
 	"io"
+	"log/slog"
 	"mime/multipart"
 	"net/http"
+	"regexp"
 	"sync"
+	"time"
 )
 
-// Context is the context instance for the request.
+// jsonpCallbackPattern is the pattern of the valid JSONP callback name, it allows
+// JavaScript identifiers and the property accessors of them, like "jQuery_123" or
+// "app.callbacks.load".
+var jsonpCallbackPattern = regexp.MustCompile(`^[a-zA-Z_$][0-9a-zA-Z_$]*(\.[a-zA-Z_$][0-9a-zA-Z_$]*)*$`)
+
+// Context is the context instance for the request. It implements the context.Context interface
+// for the calls during the request, but it's pooled and reused after the request is finished,
+// so it must not be kept by anything that outlives the handler, like the goroutines or the
+// background jobs. Use RequestContext or Copy for them instead.
 type Context struct {
 	// Request is the wrapped HTTP request.
 	Request *Request
@@ -16,6 +30,8 @@ type Context struct {
 	Response *Response
 	// app is the framework application instance.
 	app *App
+	// errors is the errors that occurred during the request.
+	errors []error
 	// handlers is the handler chain.
 	handlers HandlerChain
 	// index is the current handler index.
@@ -25,6 +41,10 @@ type Context struct {
 	isAbort bool
 	// pathVariables stores current request path variables.
 	pathVariables map[string]string
+	// requestID is the identifier of the request, it's set by the request ID middleware.
+	requestID string
+	// routePattern is the pattern of the matched route, like "/users/:id".
+	routePattern string
 	// sm is the mutex for protecting the context state.
 	sm sync.RWMutex
 	// state is the context state, it can be used to store any data and pass to
@@ -47,10 +67,13 @@ func (ctx *Context) reset(app *App, req *http.Request) {
 	ctx.Response.reset()
 
 	ctx.app = app
+	ctx.errors = nil
 	ctx.handlers = HandlerChain{}
 	ctx.index = -1
 	ctx.isAbort = false
 	ctx.pathVariables = make(map[string]string)
+	ctx.requestID = ""
+	ctx.routePattern = ""
 	ctx.state = make(map[string]any)
 
 	ctx.Use(app.handlers...)
@@ -58,12 +81,74 @@ func (ctx *Context) reset(app *App, req *http.Request) {
 
 // finalize releases the context, request, and response resources.
 func (ctx *Context) finalize() {
+	// Close the streaming body that is not written, so its resources like the goroutines of the
+	// compressed streams are released.
+	ctx.Response.closeStream()
+
 	ctx.app.reqPool.Put(ctx.Request)
 	ctx.app.resPool.Put(ctx.Response)
 
 	ctx.app.pool.Put(ctx)
 }
 
+// Copy returns a copy of the context that can be used outside the request scope, like in a
+// goroutine. The copy has its own request wrapper, response, path variables, and state, and
+// it continues the handler chain from the current handler if Next is called.
+func (ctx *Context) Copy() *Context {
+	ctx.sm.RLock()
+	defer ctx.sm.RUnlock()
+
+	cloned := &Context{
+		Request: &Request{
+			body:     ctx.Request.body,
+			bodyOnce: &sync.Once{},
+			request:  ctx.Request.request,
+		},
+		Response:      ctx.Response.clone(),
+		app:           ctx.app,
+		errors:        append([]error(nil), ctx.errors...),
+		handlers:      append(HandlerChain{}, ctx.handlers...),
+		index:         ctx.index,
+		isAbort:       ctx.isAbort,
+		pathVariables: make(map[string]string, len(ctx.pathVariables)),
+		requestID:     ctx.requestID,
+		routePattern:  ctx.routePattern,
+		state:         make(map[string]any, len(ctx.state)),
+	}
+
+	for k, v := range ctx.pathVariables {
+		cloned.pathVariables[k] = v
+	}
+	for k, v := range ctx.state {
+		cloned.state[k] = v
+	}
+
+	return cloned
+}
+
+// Adopt takes the results of the copy that created by Copy after its handlers are finished,
+// including the response, the state, the new errors, the path variables, the route pattern, and
+// the request ID. The copy should not be used after it's adopted.
+func (ctx *Context) Adopt(copied *Context) {
+	copied.sm.RLock()
+	defer copied.sm.RUnlock()
+	ctx.sm.Lock()
+	defer ctx.sm.Unlock()
+
+	ctx.Response = copied.Response
+	if len(copied.errors) > len(ctx.errors) {
+		ctx.errors = append(ctx.errors, copied.errors[len(ctx.errors):]...)
+	}
+	for k, v := range copied.pathVariables {
+		ctx.pathVariables[k] = v
+	}
+	for k, v := range copied.state {
+		ctx.state[k] = v
+	}
+	ctx.requestID = copied.requestID
+	ctx.routePattern = copied.routePattern
+}
+
 // writeResponse writes data from context to the response.
 func (ctx *Context) writeResponse(rw http.ResponseWriter) {
 	ctx.Response.write(rw)
@@ -124,16 +209,140 @@ func (ctx *Context) Use(handler ...HandlerFunc) *Context {
 	return ctx
 }
 
-// Log call the app logger with the given format and args.
+// Log call the app logger with the given format and args, the message is prefixed with the
+// request ID if it's set.
 func (ctx *Context) Log(fmt string, args ...any) {
+	if ctx.requestID != "" {
+		ctx.app.log("[%s] "+fmt, append([]any{ctx.requestID}, args...)...)
+		return
+	}
+
 	ctx.app.log(fmt, args...)
 }
 
+// RequestID returns the identifier of the request, it's empty if the request ID middleware is
+// not used.
+func (ctx *Context) RequestID() string {
+	return ctx.requestID
+}
+
+// SetRequestID sets the identifier of the request, it's used by the request ID middleware.
+func (ctx *Context) SetRequestID(id string) {
+	ctx.requestID = id
+}
+
+// RoutePattern returns the pattern of the matched route like "/users/:id", it's empty if the
+// request is not matched by a router.
+func (ctx *Context) RoutePattern() string {
+	return ctx.routePattern
+}
+
+// Logger returns the app structured logger with the attributes of the request, including the
+// request ID (if it's set), the method, the route pattern (if it's matched), and the client IP.
+//
+//	ctx.Logger().Info("user created", "user_id", user.ID)
+func (ctx *Context) Logger() *slog.Logger {
+	attrs := make([]any, 0, 8)
+	if ctx.requestID != "" {
+		attrs = append(attrs, slog.String("request_id", ctx.requestID))
+	}
+	attrs = append(attrs, slog.String("method", ctx.Method()))
+	if ctx.routePattern != "" {
+		attrs = append(attrs, slog.String("route", ctx.routePattern))
+	}
+	attrs = append(attrs, slog.String("ip", ctx.IP()))
+
+	return ctx.app.StructuredLogger().With(attrs...)
+}
+
+// AddError records an error that occurred during the request without stopping the handler
+// chain, the errors are reported by the logger middleware.
+func (ctx *Context) AddError(err error) {
+	if err == nil {
+		return
+	}
+
+	ctx.sm.Lock()
+	defer ctx.sm.Unlock()
+
+	ctx.errors = append(ctx.errors, err)
+}
+
+// Errors returns the errors that recorded by AddError.
+func (ctx *Context) Errors() []error {
+	ctx.sm.RLock()
+	defer ctx.sm.RUnlock()
+
+	return append([]error(nil), ctx.errors...)
+}
+
 // LoggerWriter returns the app logger's writer, or os.Stderr if the app logger is not set.
 func (ctx *Context) LoggerWriter() io.Writer {
 	return ctx.app.LoggerWriter()
 }
 
+// Deadline returns the deadline of the request context, it implements the context.Context
+// interface.
+//
+// Warning: the Context is reused after the request, pass RequestContext instead of the Context
+// to the calls that outlive the handler.
+func (ctx *Context) Deadline() (deadline time.Time, ok bool) {
+	return ctx.RequestContext().Deadline()
+}
+
+// Done returns a channel that's closed when the request context is canceled, it implements
+// the context.Context interface.
+//
+// Warning: the channel belongs to the current request only while the handler is running, wait
+// on RequestContext().Done() in the goroutines that outlive the handler.
+func (ctx *Context) Done() <-chan struct{} {
+	return ctx.RequestContext().Done()
+}
+
+// Err returns the error of the request context after it's canceled, it implements the
+// context.Context interface.
+//
+// Warning: it may report the error of another request after the handler returns, use
+// RequestContext().Err() outside the handler.
+func (ctx *Context) Err() error {
+	return ctx.RequestContext().Err()
+}
+
+// Value returns the value of the given key from the context state if the key is a string and
+// it exists in the state, otherwise returns the value from the request context. It implements
+// the context.Context interface.
+//
+// Warning: the state is cleared and reused by the next request after the handler returns, the
+// values that needed later should be read in the handler or attached to RequestContext.
+func (ctx *Context) Value(key any) any {
+	if k, ok := key.(string); ok {
+		if val, ok := ctx.Get(k); ok {
+			return val
+		}
+	}
+
+	return ctx.RequestContext().Value(key)
+}
+
+// RequestContext returns the context of the request, it's not reused by other requests, so it
+// can be kept by the goroutines or the calls that outlive the handler.
+//
+//	reqCtx := ctx.RequestContext()
+//	go func() {
+//		audit.Record(reqCtx, "user updated")
+//	}()
+func (ctx *Context) RequestContext() context.Context {
+	return ctx.Request.Context()
+}
+
+// WithContext replaces the context of the request, it can be used by the middlewares to
+// attach values or deadlines to the request.
+func (ctx *Context) WithContext(c context.Context) *Context {
+	ctx.Request.SetContext(c)
+
+	return ctx
+}
+
 // Get retrieves the value of the given key from the context state.
 func (ctx *Context) Get(key string) (any, bool) {
 	ctx.sm.RLock()
@@ -144,6 +353,19 @@ func (ctx *Context) Get(key string) (any, bool) {
 	return val, ok
 }
 
+// Keys returns a copy of the context state.
+func (ctx *Context) Keys() map[string]any {
+	ctx.sm.RLock()
+	defer ctx.sm.RUnlock()
+
+	keys := make(map[string]any, len(ctx.state))
+	for k, v := range ctx.state {
+		keys[k] = v
+	}
+
+	return keys
+}
+
 // Has returns true if the given key exists in the context state.
 func (ctx *Context) Has(key string) bool {
 	ctx.sm.Lock()
@@ -201,6 +423,11 @@ func (ctx *Context) IP() string {
 	return ctx.Request.IP()
 }
 
+// IsTLS reports whether the request is received over a TLS connection.
+func (ctx *Context) IsTLS() bool {
+	return ctx.Request.IsTLS()
+}
+
 // Method returns the request method.
 func (ctx *Context) Method() string {
 	return ctx.Request.Method()
@@ -221,13 +448,14 @@ func (ctx *Context) Post() string {
 	return ctx.Request.Body()
 }
 
-// PostJSON gets request body and parses to the given struct.
+// PostJSON gets request body and parses to the given struct, it returns a *BindingError if the
+// body can't be parsed.
 func (ctx *Context) PostJSON(payload any) error {
 	body := ctx.Request.Body()
 
 	err := json.Unmarshal([]byte(body), payload)
 	if err != nil {
-		return err
+		return &BindingError{Err: err}
 	}
 
 	return nil
@@ -307,6 +535,101 @@ func (ctx *Context) JSON(data any, statusCode ...int) error {
 	return ctx.send(payload, "application/json", statusCode...)
 }
 
+// IndentedJSON stringifies the given data with indentation and writes it to the response
+// body, and set the content type to "application/json".
+func (ctx *Context) IndentedJSON(data any, statusCode ...int) error {
+	payload, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ctx.send(payload, "application/json", statusCode...)
+}
+
+// PureJSON stringifies the given data without escaping HTML characters like "<", ">",
+// and "&", and writes it to the response body. It'll set the content type to "application/json".
+func (ctx *Context) PureJSON(data any, statusCode ...int) error {
+	buf := new(bytes.Buffer)
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+
+	if err := encoder.Encode(data); err != nil {
+		return err
+	}
+
+	return ctx.send(bytes.TrimSuffix(buf.Bytes(), []byte("\n")), "application/json", statusCode...)
+}
+
+// JSONP writes the given data to the response body as JSON wrapped by the callback function,
+// and set the content type to "application/javascript". It'll fall back to
+// plain JSON if the callback is empty, and return ErrInvalidJSONPCallback if the callback is
+// not a valid JavaScript identifier.
+func (ctx *Context) JSONP(callback string, data any, statusCode ...int) error {
+	if callback == "" {
+		return ctx.JSON(data, statusCode...)
+	}
+	if !jsonpCallbackPattern.MatchString(callback) {
+		return ErrInvalidJSONPCallback
+	}
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	buf := new(bytes.Buffer)
+	// The leading comment prevents the response to be interpreted as other content types
+	// like Flash.
+	buf.WriteString("/**/")
+	buf.WriteString(callback)
+	buf.WriteByte('(')
+	buf.Write(payload)
+	buf.WriteString(");")
+
+	return ctx.send(buf.Bytes(), "application/javascript", statusCode...)
+}
+
+// XML serializes and writes the given data to the response body with the XML header, and
+// set the content type to "application/xml".
+func (ctx *Context) XML(data any, statusCode ...int) error {
+	payload, err := xml.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	return ctx.send(append([]byte(xml.Header), payload...), "application/xml", statusCode...)
+}
+
+// YAML serializes and writes the given data to the response body, and set the content type
+// to "application/yaml".
+func (ctx *Context) YAML(data any, statusCode ...int) error {
+	payload, err := marshalYAML(data)
+	if err != nil {
+		return err
+	}
+
+	return ctx.send(payload, "application/yaml", statusCode...)
+}
+
+// Blob writes the given binary data to the response body, and set the content type to
+// the given value.
+func (ctx *Context) Blob(contentType string, data []byte, statusCode ...int) error {
+	return ctx.send(data, contentType, statusCode...)
+}
+
+// Stream sets the given reader as the response body, and set the content type to the
+// given value. The reader will be copied to the client after all handlers finished, and
+// it'll be closed if it implements the io.Closer interface.
+func (ctx *Context) Stream(contentType string, reader io.Reader, statusCode ...int) error {
+	if err := ctx.send(nil, contentType, statusCode...); err != nil {
+		return err
+	}
+
+	ctx.Response.SetStream(reader)
+
+	return nil
+}
+
 // HTML writes the given data to the response body as HTML, and set the content type
 // to "text/html".
 func (ctx *Context) HTML(html string, statusCode ...int) error {
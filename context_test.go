@@ -0,0 +1,228 @@
+package dolphin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testRequest makes a request to the app with the given handler, and returns
+// the recorded response.
+func testRequest(handler HandlerFunc, req *http.Request) *httptest.ResponseRecorder {
+	app := New(nil)
+	app.Use(handler)
+
+	return testServe(app, req)
+}
+
+// testServe makes a request to the app, and returns the recorded response.
+func testServe(app *App, req *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestContextRenderers(t *testing.T) {
+	type payload struct {
+		Name string `json:"name" xml:"name" yaml:"name"`
+	}
+	data := payload{Name: "<dolphin>"}
+
+	cases := []struct {
+		name        string
+		handler     HandlerFunc
+		contentType string
+		body        string
+	}{
+		{
+			name:        "IndentedJSON",
+			handler:     func(c *Context) { c.IndentedJSON(data) },
+			contentType: "application/json",
+			body:        "{\n  \"name\": \"\\u003cdolphin\\u003e\"\n}",
+		},
+		{
+			name:        "PureJSON",
+			handler:     func(c *Context) { c.PureJSON(data) },
+			contentType: "application/json",
+			body:        `{"name":"<dolphin>"}`,
+		},
+		{
+			name:        "JSONP",
+			handler:     func(c *Context) { c.JSONP("app.callback", O{"ok": true}) },
+			contentType: "application/javascript",
+			body:        `/**/app.callback({"ok":true});`,
+		},
+		{
+			name:        "XML",
+			handler:     func(c *Context) { c.XML(data) },
+			contentType: "application/xml",
+			body:        "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<payload><name>&lt;dolphin&gt;</name></payload>",
+		},
+		{
+			name:        "YAML",
+			handler:     func(c *Context) { c.YAML(data) },
+			contentType: "application/yaml",
+			body:        "name: <dolphin>\n",
+		},
+		{
+			name:        "Blob",
+			handler:     func(c *Context) { c.Blob("application/octet-stream", []byte{0x01, 0x02}) },
+			contentType: "application/octet-stream",
+			body:        "\x01\x02",
+		},
+		{
+			name: "Stream",
+			handler: func(c *Context) {
+				c.Stream("text/event-stream", strings.NewReader("data: hello\n\n"))
+			},
+			contentType: "text/event-stream",
+			body:        "data: hello\n\n",
+		},
+	}
+
+	for _, c := range cases {
+		rec := testRequest(c.handler, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		if ct := rec.Header().Get("Content-Type"); ct != c.contentType {
+			t.Errorf("%s: content type expect %q, actual %q", c.name, c.contentType, ct)
+		}
+		if body := rec.Body.String(); body != c.body {
+			t.Errorf("%s: body expect %q, actual %q", c.name, c.body, body)
+		}
+	}
+}
+
+func TestContextJSONPInvalidCallback(t *testing.T) {
+	for _, callback := range []string{"alert(1)//", "1abc", "a..b", "a b"} {
+		var err error
+
+		testRequest(func(c *Context) {
+			err = c.JSONP(callback, O{})
+		}, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		if err != ErrInvalidJSONPCallback {
+			t.Errorf("JSONP callback %q expect ErrInvalidJSONPCallback, actual %v", callback, err)
+		}
+	}
+}
+
+func TestContextAsContext(t *testing.T) {
+	type ctxKey struct{}
+
+	testRequest(func(c *Context) {
+		var _ context.Context = c
+
+		c.Set("user", "dolphin")
+		parent, cancel := context.WithTimeout(context.WithValue(c.RequestContext(), ctxKey{}, "value"), time.Minute)
+		defer cancel()
+		c.WithContext(parent)
+
+		if val := c.Value("user"); val != "dolphin" {
+			t.Errorf("Value(\"user\") expect \"dolphin\", actual %v", val)
+		}
+		if val := c.Value(ctxKey{}); val != "value" {
+			t.Errorf("Value(ctxKey{}) expect \"value\", actual %v", val)
+		}
+		if _, ok := c.Deadline(); !ok {
+			t.Errorf("Deadline expect set")
+		}
+
+		cancel()
+		<-c.Done()
+		if c.Err() != context.Canceled {
+			t.Errorf("Err expect context.Canceled, actual %v", c.Err())
+		}
+	}, httptest.NewRequest(http.MethodGet, "/", nil))
+}
+
+func TestContextRequestContextAfterRequest(t *testing.T) {
+	type ctxKey struct{}
+
+	var kept context.Context
+	app := New(nil)
+	app.Use(func(c *Context) {
+		if kept == nil {
+			kept = c.RequestContext()
+		}
+	})
+
+	first, cancel := context.WithCancel(context.WithValue(context.Background(), ctxKey{}, "first"))
+	testServe(app, httptest.NewRequest(http.MethodGet, "/", nil).WithContext(first))
+	second := context.WithValue(context.Background(), ctxKey{}, "second")
+	testServe(app, httptest.NewRequest(http.MethodGet, "/", nil).WithContext(second))
+
+	if val := kept.Value(ctxKey{}); val != "first" {
+		t.Errorf("Kept request context value expect \"first\", actual %v", val)
+	}
+
+	cancel()
+	<-kept.Done()
+	if kept.Err() != context.Canceled {
+		t.Errorf("Kept request context Err expect context.Canceled, actual %v", kept.Err())
+	}
+}
+
+func TestContextLogRequestID(t *testing.T) {
+	buf := new(bytes.Buffer)
+	app := New(&Config{Logger: log.New(buf, "", 0)})
+	app.Use(func(c *Context) {
+		c.SetRequestID("req-%d")
+		c.Log("user %s\n", "1")
+	})
+
+	testServe(app, httptest.NewRequest(http.MethodGet, "/", nil))
+	if out := buf.String(); out != "[req-%d] user 1\n" {
+		t.Errorf("Log expect \"[req-%%d] user 1\\n\", actual %q", out)
+	}
+}
+
+func TestContextLogger(t *testing.T) {
+	buf := new(bytes.Buffer)
+	app := New(&Config{StructuredLogger: slog.New(slog.NewJSONHandler(buf, nil))})
+
+	router := NewRouter()
+	router.GET("/users/:id", func(c *Context) {
+		c.SetRequestID("req-1")
+		c.Logger().Info("loaded", "user", c.PathVariable("id"))
+		c.Log("printf %d\n", 1)
+	})
+	app.Use(router.Routes())
+
+	req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	req.RemoteAddr = "127.0.0.1:1234"
+	testServe(app, req)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Logger expect 2 records, actual %q", buf.String())
+	}
+
+	var record map[string]any
+	json.Unmarshal([]byte(lines[0]), &record)
+	expected := map[string]any{
+		"msg":        "loaded",
+		"request_id": "req-1",
+		"method":     "GET",
+		"route":      "/users/:id",
+		"ip":         "127.0.0.1:1234",
+		"user":       "1",
+	}
+	for key, value := range expected {
+		if record[key] != value {
+			t.Errorf("Record %s expect %v, actual %v", key, value, record[key])
+		}
+	}
+
+	json.Unmarshal([]byte(lines[1]), &record)
+	if record["msg"] != "[req-1] printf 1" {
+		t.Errorf("Log expect written to structured logger, actual %v", record["msg"])
+	}
+}
@@ -0,0 +1,255 @@
+package dolphin
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ghosind/dolphin/internal/aead"
+)
+
+// CookieOptions is the options of the signed and encrypted cookies.
+type CookieOptions struct {
+	// DisableHTTPOnly allows the client scripts to access the cookie, the cookie is HttpOnly by
+	// default.
+	DisableHTTPOnly bool
+	// Domain is the domain of the cookie.
+	Domain string
+	// MaxAge is the lifetime of the cookie, it's a session cookie if MaxAge is zero. The
+	// expiration time is also stored in the signed or encrypted value, so it can't be extended
+	// by the client.
+	MaxAge time.Duration
+	// Path is the path of the cookie, default "/".
+	Path string
+	// SameSite is the SameSite attribute of the cookie, default http.SameSiteLaxMode.
+	SameSite http.SameSite
+	// Secure indicates the cookie is only sent over HTTPS, it's always set for the requests over
+	// TLS.
+	Secure bool
+}
+
+// cookieKeys is the derived keys for the signed and encrypted cookies.
+type cookieKeys struct {
+	cipher  *aead.Cipher
+	signing [][]byte
+}
+
+// newCookieKeys derives the signing keys and the encryption keys from the secrets, so a secret
+// is never used for both purposes.
+func newCookieKeys(secrets [][]byte) *cookieKeys {
+	if len(secrets) == 0 {
+		return nil
+	}
+
+	keys := &cookieKeys{
+		signing: make([][]byte, 0, len(secrets)),
+	}
+	encryption := make([][]byte, 0, len(secrets))
+
+	for _, secret := range secrets {
+		keys.signing = append(keys.signing, deriveCookieKey(secret, "signed cookie"))
+		encryption = append(encryption, deriveCookieKey(secret, "encrypted cookie"))
+	}
+
+	// The derived keys are always 32 bytes, so they're valid AES-256 keys, and GCM with the
+	// standard nonce size can't fail for an AES block.
+	c, err := aead.New(encryption...)
+	if err != nil {
+		panic("dolphin: " + err.Error())
+	}
+	keys.cipher = c
+
+	return keys
+}
+
+// deriveCookieKey derives a 256-bit key for the purpose from the secret.
+func deriveCookieKey(secret []byte, purpose string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte("dolphin " + purpose))
+
+	return mac.Sum(nil)
+}
+
+// SetSignedCookie sets a cookie that signed by HMAC-SHA256 with the first key in
+// Config.CookieKeys. The value can be read by the client, but it can't be tampered.
+//
+//	ctx.SetSignedCookie("theme", "dark", dolphin.CookieOptions{MaxAge: 30 * 24 * time.Hour})
+func (ctx *Context) SetSignedCookie(name, value string, options ...CookieOptions) error {
+	keys := ctx.app.cookieKeys
+	if keys == nil {
+		return ErrNoCookieKeys
+	}
+
+	opts := getCookieOptions(options)
+	payload := encodeCookiePayload(value, opts.MaxAge)
+	encoded := base64.RawURLEncoding.EncodeToString([]byte(payload)) + "." +
+		base64.RawURLEncoding.EncodeToString(signCookie(keys.signing[0], name, payload))
+
+	ctx.setCookie(name, encoded, opts)
+
+	return nil
+}
+
+// SignedCookie returns the value of the cookie that set by SetSignedCookie. It returns
+// http.ErrNoCookie if the cookie is not found, ErrInvalidCookie if the signature is invalid,
+// or ErrCookieExpired if the cookie is expired.
+func (ctx *Context) SignedCookie(name string) (string, error) {
+	keys := ctx.app.cookieKeys
+	if keys == nil {
+		return "", ErrNoCookieKeys
+	}
+
+	cookie, err := ctx.Cookie(name)
+	if err != nil {
+		return "", err
+	}
+
+	encodedPayload, encodedSignature, ok := strings.Cut(cookie.Value, ".")
+	if !ok {
+		return "", ErrInvalidCookie
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return "", ErrInvalidCookie
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+	if err != nil {
+		return "", ErrInvalidCookie
+	}
+
+	for _, key := range keys.signing {
+		if hmac.Equal(signCookie(key, name, string(payload)), signature) {
+			return decodeCookiePayload(string(payload))
+		}
+	}
+
+	return "", ErrInvalidCookie
+}
+
+// SetEncryptedCookie sets a cookie that encrypted by AES-GCM with the first key in
+// Config.CookieKeys. The value can't be read or tampered by the client.
+func (ctx *Context) SetEncryptedCookie(name, value string, options ...CookieOptions) error {
+	keys := ctx.app.cookieKeys
+	if keys == nil {
+		return ErrNoCookieKeys
+	}
+
+	opts := getCookieOptions(options)
+	payload := encodeCookiePayload(value, opts.MaxAge)
+
+	sealed, err := keys.cipher.Seal([]byte(payload), []byte(name))
+	if err != nil {
+		return err
+	}
+
+	ctx.setCookie(name, base64.RawURLEncoding.EncodeToString(sealed), opts)
+
+	return nil
+}
+
+// EncryptedCookie returns the decrypted value of the cookie that set by SetEncryptedCookie. It
+// returns http.ErrNoCookie if the cookie is not found, ErrInvalidCookie if the cookie can't be
+// decrypted, or ErrCookieExpired if the cookie is expired.
+func (ctx *Context) EncryptedCookie(name string) (string, error) {
+	keys := ctx.app.cookieKeys
+	if keys == nil {
+		return "", ErrNoCookieKeys
+	}
+
+	cookie, err := ctx.Cookie(name)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(cookie.Value)
+	if err != nil {
+		return "", ErrInvalidCookie
+	}
+
+	payload, ok := keys.cipher.Open(data, []byte(name))
+	if !ok {
+		return "", ErrInvalidCookie
+	}
+
+	return decodeCookiePayload(string(payload))
+}
+
+// setCookie adds the cookie with the options to the response.
+func (ctx *Context) setCookie(name, value string, opts CookieOptions) {
+	cookie := &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     opts.Path,
+		Domain:   opts.Domain,
+		Secure:   opts.Secure || ctx.IsTLS(),
+		HttpOnly: !opts.DisableHTTPOnly,
+		SameSite: opts.SameSite,
+	}
+	if opts.MaxAge > 0 {
+		cookie.MaxAge = int(opts.MaxAge / time.Second)
+		cookie.Expires = time.Now().Add(opts.MaxAge)
+	}
+
+	ctx.AddCookies(cookie)
+}
+
+// signCookie returns the HMAC-SHA256 signature of the cookie name and the payload.
+func signCookie(key []byte, name, payload string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(name))
+	mac.Write([]byte{'='})
+	mac.Write([]byte(payload))
+
+	return mac.Sum(nil)
+}
+
+// encodeCookiePayload encodes the value with the expiration time in the form of
+// "<expires>|<value>", the expiration time is zero if it's a session cookie.
+func encodeCookiePayload(value string, maxAge time.Duration) string {
+	var expires int64
+	if maxAge > 0 {
+		expires = time.Now().Add(maxAge).Unix()
+	}
+
+	return strconv.FormatInt(expires, 10) + "|" + value
+}
+
+// decodeCookiePayload decodes the payload that encoded by encodeCookiePayload, and checks the
+// expiration time.
+func decodeCookiePayload(payload string) (string, error) {
+	expiresStr, value, ok := strings.Cut(payload, "|")
+	if !ok {
+		return "", ErrInvalidCookie
+	}
+
+	expires, err := strconv.ParseInt(expiresStr, 10, 64)
+	if err != nil {
+		return "", ErrInvalidCookie
+	}
+	if expires > 0 && time.Now().Unix() >= expires {
+		return "", ErrCookieExpired
+	}
+
+	return value, nil
+}
+
+// getCookieOptions returns the cookie options with the default values.
+func getCookieOptions(options []CookieOptions) CookieOptions {
+	var opts CookieOptions
+	if len(options) > 0 {
+		opts = options[0]
+	}
+
+	if opts.Path == "" {
+		opts.Path = "/"
+	}
+	if opts.SameSite == 0 {
+		opts.SameSite = http.SameSiteLaxMode
+	}
+
+	return opts
+}
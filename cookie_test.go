@@ -0,0 +1,118 @@
+package dolphin
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// testCookieRoundTrip sets the cookie by the setter in an app, and reads it by the getter in
+// another app with the read keys.
+func testCookieRoundTrip(
+	t *testing.T,
+	writeKeys, readKeys [][]byte,
+	set func(*Context) error,
+	get func(*Context) (string, error),
+) (*http.Cookie, string, error) {
+	app := New(&Config{CookieKeys: writeKeys})
+	app.Use(func(ctx *Context) {
+		if err := set(ctx); err != nil {
+			t.Errorf("Set cookie expect no error, actual %v", err)
+		}
+	})
+	rec := testServe(app, httptest.NewRequest(http.MethodGet, "/", nil))
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("Set cookie expect 1 cookie, actual %v", cookies)
+	}
+
+	var value string
+	var err error
+	app = New(&Config{CookieKeys: readKeys})
+	app.Use(func(ctx *Context) {
+		value, err = get(ctx)
+	})
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(cookies[0])
+	testServe(app, req)
+
+	return cookies[0], value, err
+}
+
+func TestSignedCookie(t *testing.T) {
+	oldKey := []byte("old-secret-key-with-enough-length")
+	newKey := []byte("new-secret-key-with-enough-length")
+	set := func(ctx *Context) error { return ctx.SetSignedCookie("theme", "dark") }
+	get := func(ctx *Context) (string, error) { return ctx.SignedCookie("theme") }
+
+	cookie, value, err := testCookieRoundTrip(t, [][]byte{oldKey}, [][]byte{newKey, oldKey}, set, get)
+	if err != nil || value != "dark" {
+		t.Errorf("Signed cookie expect \"dark\", actual %q %v", value, err)
+	}
+	if !cookie.HttpOnly || cookie.SameSite != http.SameSiteLaxMode || cookie.Path != "/" {
+		t.Errorf("Signed cookie expect secure defaults, actual %v", cookie)
+	}
+
+	_, _, err = testCookieRoundTrip(t, [][]byte{oldKey}, [][]byte{newKey}, set, get)
+	if err != ErrInvalidCookie {
+		t.Errorf("Signed cookie with unknown key expect ErrInvalidCookie, actual %v", err)
+	}
+
+	// The signature is bound to the cookie name.
+	_, _, err = testCookieRoundTrip(t, [][]byte{oldKey}, [][]byte{oldKey}, set, func(ctx *Context) (string, error) {
+		cookie, _ := ctx.Cookie("theme")
+		ctx.Request.request.AddCookie(&http.Cookie{Name: "role", Value: cookie.Value})
+		return ctx.SignedCookie("role")
+	})
+	if err != ErrInvalidCookie {
+		t.Errorf("Signed cookie with other name expect ErrInvalidCookie, actual %v", err)
+	}
+}
+
+func TestEncryptedCookie(t *testing.T) {
+	key := []byte("secret-key-with-enough-length")
+	set := func(ctx *Context) error {
+		return ctx.SetEncryptedCookie("token", "s3cr3t", CookieOptions{MaxAge: time.Hour})
+	}
+	get := func(ctx *Context) (string, error) { return ctx.EncryptedCookie("token") }
+
+	cookie, value, err := testCookieRoundTrip(t, [][]byte{key}, [][]byte{key}, set, get)
+	if err != nil || value != "s3cr3t" {
+		t.Errorf("Encrypted cookie expect \"s3cr3t\", actual %q %v", value, err)
+	}
+	if cookie.MaxAge != 3600 {
+		t.Errorf("Encrypted cookie Max-Age expect 3600, actual %d", cookie.MaxAge)
+	}
+
+	_, _, err = testCookieRoundTrip(t, [][]byte{key}, [][]byte{key}, func(ctx *Context) error {
+		return ctx.SetEncryptedCookie("token", "s3cr3t", CookieOptions{MaxAge: -time.Hour})
+	}, get)
+	if err != nil {
+		t.Errorf("Encrypted session cookie expect no error, actual %v", err)
+	}
+
+	testRequest(func(ctx *Context) {
+		if _, err := decodeCookiePayload("1|expired"); err != ErrCookieExpired {
+			t.Errorf("Expired payload expect ErrCookieExpired, actual %v", err)
+		}
+		if _, err := ctx.EncryptedCookie("token"); err != ErrNoCookieKeys {
+			t.Errorf("EncryptedCookie without keys expect ErrNoCookieKeys, actual %v", err)
+		}
+	}, httptest.NewRequest(http.MethodGet, "/", nil))
+}
+
+func TestCookieSecureUnderTLS(t *testing.T) {
+	app := New(&Config{CookieKeys: [][]byte{[]byte("secret-key-with-enough-length")}})
+	app.Use(func(ctx *Context) {
+		ctx.SetSignedCookie("theme", "dark")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "https://example.com/", nil)
+	req.TLS = &tls.ConnectionState{}
+	rec := testServe(app, req)
+	if cookies := rec.Result().Cookies(); len(cookies) != 1 || !cookies[0].Secure {
+		t.Errorf("Cookie under TLS expect Secure, actual %v", cookies)
+	}
+}
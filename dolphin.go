@@ -2,13 +2,19 @@ package dolphin
 
 import (
 	"log"
+	"log/slog"
 	"net/http"
+	"os"
 	"sync"
+	"time"
 )
 
 // HandlerFunc is the function that register as a handler to the app.
 type HandlerFunc func(*Context)
 
+// ErrorHandler is the function to handle the errors that reported by Context.Error.
+type ErrorHandler func(ctx *Context, err error)
+
 // HandlerChain is a chain of handlers.
 type HandlerChain []HandlerFunc
 
@@ -19,13 +25,38 @@ type O map[string]any
 type Config struct {
 	// CertFile is the TLS certificate file.
 	CertFile *string
+	// CookieKeys is the secrets to sign and encrypt the cookies by Context.SetSignedCookie and
+	// Context.SetEncryptedCookie. The first key is used to sign and encrypt the new cookies, and
+	// all keys are used to verify and decrypt, so the keys can be rotated by prepending a new
+	// key. The keys should be random and at least 32 bytes.
+	CookieKeys [][]byte
+	// ErrorHandler handles the errors that reported by Context.Error, it should write the error
+	// response. Dolphin will use DefaultErrorHandler if this have not set.
+	ErrorHandler ErrorHandler
 	// KeyFile is the TLS private key file.
 	KeyFile *string
-	// Logger is the logger used by the app, dolphin will use log.Printf if this
-	// have not set.
+	// Logger is the logger used by the app, Dolphin will use log.Printf if this have not set. A
+	// text structured logger that writes to the logger's writer is also created if the
+	// StructuredLogger have not set.
 	Logger *log.Logger
 	// Port is the port to listen on.
 	Port int
+	// ProblemDetails enables the RFC 9457 problem details ("application/problem+json") for the
+	// error responses of the default error handler, the default not found handler, the default
+	// method not allowed handler, and the recover middleware.
+	ProblemDetails bool
+	// StructuredLogger is the structured logger used by the app and Context.Logger, Dolphin will
+	// use slog.Default() if this have not set. The app logger writes the messages to it at the
+	// info level if the Logger have not set.
+	StructuredLogger *slog.Logger
+	// ShutdownHookTimeout is the timeout of the OnShutdown hooks, it starts after the in-flight
+	// requests are drained, default 15 seconds.
+	ShutdownHookTimeout time.Duration
+	// StartTimeout is the timeout of the OnStart and OnReady hooks, default 15 seconds.
+	StartTimeout time.Duration
+	// UnixSocketMode is the file mode of the Unix domain socket that created by App.Listen, like
+	// 0660. The mode is determined by the umask if it's not set.
+	UnixSocketMode os.FileMode
 }
 
 // debugMode indicates the enable/disable status of debug mode.
@@ -42,12 +73,17 @@ func New(config *Config) *App {
 		config = &Config{}
 	}
 
-	return &App{
-		certFile: config.CertFile,
-		keyFile:  config.KeyFile,
-		logger:   config.Logger,
-		port:     config.Port,
-		handlers: HandlerChain{},
+	app := &App{
+		certFile:            config.CertFile,
+		cookieKeys:          newCookieKeys(config.CookieKeys),
+		errorHandler:        config.ErrorHandler,
+		keyFile:             config.KeyFile,
+		port:                config.Port,
+		problemDetails:      config.ProblemDetails,
+		shutdownHookTimeout: config.ShutdownHookTimeout,
+		startTimeout:        config.StartTimeout,
+		unixSocketMode:      config.UnixSocketMode,
+		handlers:            HandlerChain{},
 		pool: &sync.Pool{
 			New: func() any {
 				return allocateContext()
@@ -65,6 +101,25 @@ func New(config *Config) *App {
 		},
 		server: &http.Server{},
 	}
+
+	if app.shutdownHookTimeout <= 0 {
+		app.shutdownHookTimeout = defaultShutdownHookTimeout
+	}
+	if app.startTimeout <= 0 {
+		app.startTimeout = defaultStartTimeout
+	}
+
+	if config.Logger != nil {
+		app.SetLogger(config.Logger)
+	}
+	if config.StructuredLogger != nil {
+		app.slogger = config.StructuredLogger
+		if config.Logger == nil {
+			app.SetStructuredLogger(config.StructuredLogger)
+		}
+	}
+
+	return app
 }
 
 // Default creates a new App instance with default configuration.
@@ -10,3 +10,45 @@ var ErrNoTLSKey = errors.New("no TLS key file")
 
 // ErrInvalidStatusCode is returned by the response status code is not valid.
 var ErrInvalidStatusCode = errors.New("invalid status code")
+
+// ErrInvalidJSONPCallback is returned by the JSONP renderer when the callback name is not a
+// valid JavaScript identifier.
+var ErrInvalidJSONPCallback = errors.New("invalid JSONP callback")
+
+// ErrInvalidFilePath is returned when the file path to serve is invalid, for example, it
+// contains ".." elements.
+var ErrInvalidFilePath = errors.New("invalid file path")
+
+// ErrFileIsDirectory is returned when the file to send is a directory.
+var ErrFileIsDirectory = errors.New("file is a directory")
+
+// ErrNoCookieKeys is returned by the signed and encrypted cookie methods when Config.CookieKeys
+// is not set.
+var ErrNoCookieKeys = errors.New("no cookie keys")
+
+// ErrInvalidCookie is returned when the signed or encrypted cookie is tampered, or it's signed or
+// encrypted by an unknown key.
+var ErrInvalidCookie = errors.New("invalid cookie value")
+
+// ErrCookieExpired is returned when the signed or encrypted cookie is expired.
+var ErrCookieExpired = errors.New("cookie expired")
+
+// ErrInvalidListenAddr is returned by App.Listen when the address is invalid, like a Unix domain
+// socket address without the path.
+var ErrInvalidListenAddr = errors.New("invalid listen address")
+
+// ErrSocketInUse is returned by App.Listen when the Unix domain socket is accepting connections
+// by another process.
+var ErrSocketInUse = errors.New("unix socket is in use")
+
+// ErrNotSocket is returned by App.Listen when the path of the Unix domain socket exists and it's
+// not a socket.
+var ErrNotSocket = errors.New("file exists and is not a socket")
+
+// ErrInvalidListenFds is returned when the "LISTEN_FDS" environment variable of the systemd
+// socket activation is invalid.
+var ErrInvalidListenFds = errors.New("invalid LISTEN_FDS")
+
+// ErrNoSystemdListener is returned by App.Listen when no socket or no socket with the name is
+// passed by the systemd socket activation.
+var ErrNoSystemdListener = errors.New("no systemd socket")
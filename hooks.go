@@ -0,0 +1,259 @@
+package dolphin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+// defaultStartTimeout is the default timeout of the start and ready hooks.
+const defaultStartTimeout = 15 * time.Second
+
+// defaultShutdownHookTimeout is the default timeout of the shutdown hooks.
+const defaultShutdownHookTimeout = 15 * time.Second
+
+// StartHook is the function that called before the app starts listening, it should acquire the
+// resources before the context is done.
+type StartHook func(ctx context.Context) error
+
+// ReadyHook is the function that called after the app is bound to the address, and before it
+// starts serving the requests.
+type ReadyHook func(ctx context.Context, addr net.Addr) error
+
+// ShutdownHook is the function that called when the app is shutting down, it should release the
+// resources before the context is done.
+type ShutdownHook func(ctx context.Context) error
+
+// RouteHook is the function that called when a route is registered.
+type RouteHook func(route Route)
+
+// Route is the information of a registered route.
+type Route struct {
+	// Handlers is the handlers of the route.
+	Handlers HandlerChain
+	// Method is the HTTP method of the route.
+	Method string
+	// Path is the path pattern of the route, like "/users/:id".
+	Path string
+}
+
+// appHooks is the lifecycle hooks of the app.
+type appHooks struct {
+	mu       sync.Mutex
+	ready    []ReadyHook
+	route    []RouteHook
+	routes   []Route
+	shutdown []ShutdownHook
+	start    []StartHook
+}
+
+// OnStart registers one or more hooks that called in the order of registration before the app
+// starts listening, like opening the database pools. If a hook returns an error or doesn't
+// return before Config.StartTimeout, the startup is aborted, and only the shutdown hooks that
+// registered by the succeeded start and ready hooks are called to release the acquired
+// resources. The shutdown hooks that registered before the startup are kept for Shutdown.
+//
+//	app.OnStart(func(ctx context.Context) error {
+//		db, err := openDatabase(ctx)
+//		if err != nil {
+//			return err
+//		}
+//		app.OnShutdown(func(ctx context.Context) error {
+//			return db.Close()
+//		})
+//		return nil
+//	})
+func (app *App) OnStart(hook ...StartHook) *App {
+	app.hooks.mu.Lock()
+	defer app.hooks.mu.Unlock()
+
+	app.hooks.start = append(app.hooks.start, hook...)
+
+	return app
+}
+
+// OnReady registers one or more hooks that called in the order of registration after the app is
+// bound to the address, and before it starts serving the requests. The address is the actual
+// address of the listener, like the port chosen by the system for ":0". The startup is aborted
+// like OnStart if a hook fails.
+func (app *App) OnReady(hook ...ReadyHook) *App {
+	app.hooks.mu.Lock()
+	defer app.hooks.mu.Unlock()
+
+	app.hooks.ready = append(app.hooks.ready, hook...)
+
+	return app
+}
+
+// OnShutdown registers one or more hooks that called by Shutdown after the server stopped
+// accepting and the in-flight requests finished. The hooks are called in the reverse order of
+// registration, so the resources can be released in the reverse order of acquisition. Each hook
+// is called once, even if Shutdown is called multiple times. The hooks share the budget of
+// Config.ShutdownHookTimeout, they're still called if draining the requests timed out.
+//
+//	db := openDatabase()
+//	app.OnShutdown(func(ctx context.Context) error {
+//		return db.Close()
+//	})
+func (app *App) OnShutdown(hook ...ShutdownHook) *App {
+	app.hooks.mu.Lock()
+	defer app.hooks.mu.Unlock()
+
+	app.hooks.shutdown = append(app.hooks.shutdown, hook...)
+
+	return app
+}
+
+// OnRoute registers one or more hooks that called in the order of registration when a route is
+// registered to the routers that created by App.NewRouter. The hooks are also called for the
+// routes that registered before, so the middleware packages can register the hooks at any time.
+//
+// The routers that created by the package-level NewRouter are not attached to the app, so their
+// routes can't be reported to the hooks, and their handlers panic when they're used by an app
+// that has the route hooks.
+//
+//	app.OnRoute(func(route dolphin.Route) {
+//		metrics.Register(route.Method, route.Path)
+//	})
+func (app *App) OnRoute(hook ...RouteHook) *App {
+	app.hooks.mu.Lock()
+	app.hooks.route = append(app.hooks.route, hook...)
+	routes := append([]Route(nil), app.hooks.routes...)
+	app.hooks.mu.Unlock()
+
+	for _, route := range routes {
+		for _, h := range hook {
+			h(route)
+		}
+	}
+
+	return app
+}
+
+// NewRouter creates a router like NewRouter, and the routes that registered to it are reported to
+// the OnRoute hooks of the app.
+func (app *App) NewRouter(config ...RouterConfig) *Router {
+	router := NewRouter(config...)
+	router.app = app
+	router.OnRoute(app.runRouteHooks)
+
+	return router
+}
+
+// hasRouteHooks reports whether the app has the route hooks.
+func (app *App) hasRouteHooks() bool {
+	app.hooks.mu.Lock()
+	defer app.hooks.mu.Unlock()
+
+	return len(app.hooks.route) > 0
+}
+
+// runRouteHooks records the route, and calls the registered route hooks with it.
+func (app *App) runRouteHooks(route Route) {
+	app.hooks.mu.Lock()
+	app.hooks.routes = append(app.hooks.routes, route)
+	hooks := append([]RouteHook(nil), app.hooks.route...)
+	app.hooks.mu.Unlock()
+
+	for _, hook := range hooks {
+		hook(route)
+	}
+}
+
+// runStartHooks calls the registered start hooks in order, and returns the error of the first
+// failed hook. The shutdown hooks that registered by the failed hook are discarded.
+func (app *App) runStartHooks(ctx context.Context) error {
+	app.hooks.mu.Lock()
+	hooks := append([]StartHook(nil), app.hooks.start...)
+	app.hooks.mu.Unlock()
+
+	for i, hook := range hooks {
+		mark := app.shutdownHookMark()
+		if err := runHook(ctx, hook); err != nil {
+			app.discardShutdownHooks(mark)
+			return fmt.Errorf("start hook #%d: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// runReadyHooks calls the registered ready hooks in order with the address, and returns the error
+// of the first failed hook. The shutdown hooks that registered by the failed hook are discarded.
+func (app *App) runReadyHooks(ctx context.Context, addr net.Addr) error {
+	app.hooks.mu.Lock()
+	hooks := append([]ReadyHook(nil), app.hooks.ready...)
+	app.hooks.mu.Unlock()
+
+	for i, hook := range hooks {
+		mark := app.shutdownHookMark()
+		err := runHook(ctx, func(ctx context.Context) error {
+			return hook(ctx, addr)
+		})
+		if err != nil {
+			app.discardShutdownHooks(mark)
+			return fmt.Errorf("ready hook #%d: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// runShutdownHooks calls the shutdown hooks that registered after the mark in the reverse order,
+// and returns the combined errors of the hooks. The called hooks are removed, and the hooks
+// before the mark are kept.
+func (app *App) runShutdownHooks(ctx context.Context, mark int) error {
+	app.hooks.mu.Lock()
+	mark = min(mark, len(app.hooks.shutdown))
+	hooks := app.hooks.shutdown[mark:]
+	app.hooks.shutdown = app.hooks.shutdown[:mark:mark]
+	app.hooks.mu.Unlock()
+
+	errs := make([]error, 0)
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if err := runHook(ctx, hooks[i]); err != nil {
+			errs = append(errs, fmt.Errorf("shutdown hook #%d: %w", mark+i, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// shutdownHookMark returns the number of the registered shutdown hooks, the hooks that registered
+// after it can be called or discarded by the mark.
+func (app *App) shutdownHookMark() int {
+	app.hooks.mu.Lock()
+	defer app.hooks.mu.Unlock()
+
+	return len(app.hooks.shutdown)
+}
+
+// discardShutdownHooks removes the shutdown hooks that registered after the mark without calling
+// them.
+func (app *App) discardShutdownHooks(mark int) {
+	app.hooks.mu.Lock()
+	defer app.hooks.mu.Unlock()
+
+	if mark < len(app.hooks.shutdown) {
+		app.hooks.shutdown = app.hooks.shutdown[:mark:mark]
+	}
+}
+
+// runHook calls the hook with the context, and stops waiting for the hook if the context is done
+// before it returns.
+func runHook(ctx context.Context, hook func(ctx context.Context) error) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- hook(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
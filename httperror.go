@@ -0,0 +1,196 @@
+package dolphin
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// HTTPError is an error with the HTTP status code and the information for the clients. The
+// errors that are not HTTPError are handled as 500 (Internal Server Error) by the default error
+// handler, and their messages are not sent to the clients.
+//
+//	return dolphin.NewHTTPError(http.StatusNotFound, "user not found")
+type HTTPError struct {
+	// Code is the application-specific error code, like "user_not_found".
+	Code string `json:"code,omitempty"`
+	// Details is the additional information of the error, like the invalid fields.
+	Details any `json:"details,omitempty"`
+	// Err is the internal error that caused the HTTP error, it's not sent to the clients.
+	Err error `json:"-"`
+	// Message is the error message for the clients, default the status text.
+	Message string `json:"message"`
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int `json:"-"`
+}
+
+// NewHTTPError creates an HTTP error with the status code and the optional message, the message
+// is the status text if it's not set.
+func NewHTTPError(statusCode int, message ...string) *HTTPError {
+	err := &HTTPError{StatusCode: statusCode}
+	if len(message) > 0 {
+		err.Message = message[0]
+	} else {
+		err.Message = http.StatusText(statusCode)
+	}
+
+	return err
+}
+
+// Error returns the error message.
+func (err *HTTPError) Error() string {
+	msg := "code=" + strconv.Itoa(err.StatusCode) + ", message=" + err.Message
+	if err.Err != nil {
+		msg += ", error=" + err.Err.Error()
+	}
+
+	return msg
+}
+
+// Unwrap returns the internal error.
+func (err *HTTPError) Unwrap() error {
+	return err.Err
+}
+
+// WithCode sets the application-specific error code, and returns the error itself.
+func (err *HTTPError) WithCode(code string) *HTTPError {
+	err.Code = code
+	return err
+}
+
+// WithDetails sets the additional information of the error, and returns the error itself.
+func (err *HTTPError) WithDetails(details any) *HTTPError {
+	err.Details = details
+	return err
+}
+
+// WithInternal sets the internal error that caused the HTTP error, and returns the error itself.
+func (err *HTTPError) WithInternal(internal error) *HTTPError {
+	err.Err = internal
+	return err
+}
+
+// BindingError is the error that the request body can't be parsed, it's returned by
+// Context.PostJSON. It's handled as 400 (Bad Request) by the default error handler.
+type BindingError struct {
+	// Err is the error of the parser.
+	Err error
+}
+
+// Error returns the error message of the parser.
+func (err *BindingError) Error() string {
+	return err.Err.Error()
+}
+
+// Unwrap returns the error of the parser.
+func (err *BindingError) Unwrap() error {
+	return err.Err
+}
+
+// WithError adapts the handler that returns an error to HandlerFunc, the returned error is
+// reported by Context.Error.
+//
+//	router.GET("/users/:id", dolphin.WithError(func(ctx *dolphin.Context) error {
+//		user, err := findUser(ctx.PathVariable("id"))
+//		if err != nil {
+//			return err
+//		}
+//		return ctx.JSON(user)
+//	}))
+func WithError(handler func(ctx *Context) error) HandlerFunc {
+	return func(ctx *Context) {
+		if err := handler(ctx); err != nil {
+			ctx.Error(err)
+		}
+	}
+}
+
+// Error records the error, stops the handler chain, and handles the error by the app error
+// handler. It does nothing if the error is nil.
+func (ctx *Context) Error(err error) {
+	if err == nil {
+		return
+	}
+
+	ctx.AddError(err)
+	ctx.Abort()
+
+	handler := ctx.app.errorHandler
+	if handler == nil {
+		handler = DefaultErrorHandler
+	}
+	handler(ctx, err)
+}
+
+// DefaultErrorHandler discards the partial response, and renders the error as JSON if the client
+// accepts JSON, or as plain text otherwise. The status code and the message are taken from the
+// HTTPError in the error chain, or 500 (Internal Server Error) for the other errors. The errors
+// are rendered as problem details if Config.ProblemDetails is enabled or the error is a Problem.
+func DefaultErrorHandler(ctx *Context, err error) {
+	ctx.Response.ResetBody()
+	ctx.Response.DelHeader("Content-Encoding")
+	ctx.Response.DelHeader("Content-Length")
+
+	var problem *Problem
+	if ctx.ProblemDetailsEnabled() || errors.As(err, &problem) {
+		ctx.Problem(errorToProblem(err))
+		return
+	}
+
+	var httpErr *HTTPError
+	var bindingErr *BindingError
+	if errors.As(err, &bindingErr) {
+		httpErr = NewHTTPError(http.StatusBadRequest, bindingErr.Error())
+	} else if !errors.As(err, &httpErr) {
+		httpErr = NewHTTPError(http.StatusInternalServerError)
+	}
+
+	statusCode := errorStatusCode(httpErr.StatusCode)
+	if acceptsJSON(ctx) {
+		ctx.JSON(httpErr, statusCode)
+	} else {
+		ctx.String(httpErr.Message, statusCode)
+	}
+}
+
+// errorStatusCode returns the status code if it's an error status code (4xx or 5xx), or 500
+// (Internal Server Error) otherwise.
+func errorStatusCode(statusCode int) int {
+	if statusCode < 400 || statusCode > 599 {
+		return http.StatusInternalServerError
+	}
+
+	return statusCode
+}
+
+// acceptsJSON reports whether the client prefers JSON to plain text by the "Accept" header.
+func acceptsJSON(ctx *Context) bool {
+	jsonQ, textQ := -1.0, -1.0
+
+	for _, accept := range ctx.MultiValuesHeader("Accept") {
+		for _, mediaRange := range strings.Split(accept, ",") {
+			mediaType, params, _ := strings.Cut(mediaRange, ";")
+			mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+
+			q := 1.0
+			for _, param := range strings.Split(params, ";") {
+				key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+				if key == "q" {
+					if v, err := strconv.ParseFloat(value, 64); err == nil {
+						q = v
+					}
+				}
+			}
+
+			switch {
+			case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+				jsonQ = max(jsonQ, q)
+			case mediaType == "text/plain" || mediaType == "text/*":
+				textQ = max(textQ, q)
+			}
+		}
+	}
+
+	return jsonQ > 0 && jsonQ >= textQ
+}
@@ -0,0 +1,151 @@
+package dolphin
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWithError(t *testing.T) {
+	internal := errors.New("connection refused")
+
+	cases := []struct {
+		name        string
+		err         error
+		accept      string
+		statusCode  int
+		contentType string
+		body        string
+	}{
+		{
+			name:        "NoError",
+			err:         nil,
+			statusCode:  http.StatusOK,
+			contentType: "text/plain",
+			body:        "ok",
+		},
+		{
+			name:        "HTTPErrorText",
+			err:         NewHTTPError(http.StatusNotFound, "user not found"),
+			statusCode:  http.StatusNotFound,
+			contentType: "text/plain",
+			body:        "user not found",
+		},
+		{
+			name:        "HTTPErrorJSON",
+			err:         NewHTTPError(http.StatusBadRequest).WithCode("invalid").WithDetails(O{"field": "name"}),
+			accept:      "application/json",
+			statusCode:  http.StatusBadRequest,
+			contentType: "application/json",
+			body:        `{"code":"invalid","details":{"field":"name"},"message":"Bad Request"}`,
+		},
+		{
+			name:        "WrappedHTTPError",
+			err:         NewHTTPError(http.StatusConflict).WithInternal(internal),
+			accept:      "text/html, application/json;q=0.9",
+			statusCode:  http.StatusConflict,
+			contentType: "application/json",
+			body:        `{"message":"Conflict"}`,
+		},
+		{
+			name:        "InternalError",
+			err:         internal,
+			accept:      "application/json",
+			statusCode:  http.StatusInternalServerError,
+			contentType: "application/json",
+			body:        `{"message":"Internal Server Error"}`,
+		},
+		{
+			name:        "PreferText",
+			err:         internal,
+			accept:      "application/json;q=0.5, text/plain",
+			statusCode:  http.StatusInternalServerError,
+			contentType: "text/plain",
+			body:        "Internal Server Error",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var called bool
+
+			app := New(nil)
+			app.Use(WithError(func(ctx *Context) error {
+				if c.err != nil {
+					ctx.String("partial")
+					return c.err
+				}
+				return ctx.String("ok")
+			}))
+			app.Use(func(ctx *Context) {
+				called = true
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if c.accept != "" {
+				req.Header.Set("Accept", c.accept)
+			}
+			rec := testServe(app, req)
+
+			if rec.Code != c.statusCode {
+				t.Errorf("status code expect %d, actual %d", c.statusCode, rec.Code)
+			}
+			if contentType := rec.Header().Get("Content-Type"); contentType != c.contentType {
+				t.Errorf("content type expect %q, actual %q", c.contentType, contentType)
+			}
+			if body := rec.Body.String(); body != c.body {
+				t.Errorf("body expect %q, actual %q", c.body, body)
+			}
+			if called != (c.err == nil) {
+				t.Errorf("next handler called expect %v, actual %v", c.err == nil, called)
+			}
+		})
+	}
+}
+
+func TestContextErrorWithCustomHandler(t *testing.T) {
+	internal := errors.New("something wrong")
+
+	var handled error
+	var recorded []error
+
+	app := New(&Config{
+		ErrorHandler: func(ctx *Context, err error) {
+			handled = err
+			ctx.String("custom", http.StatusTeapot)
+		},
+	})
+	app.Use(func(ctx *Context) {
+		ctx.Next()
+		recorded = ctx.Errors()
+	})
+	app.Use(func(ctx *Context) {
+		ctx.Error(nil)
+		ctx.Error(internal)
+	})
+
+	rec := testServe(app, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if rec.Code != http.StatusTeapot || rec.Body.String() != "custom" {
+		t.Errorf("response expect 418 custom, actual %d %s", rec.Code, rec.Body.String())
+	}
+	if handled != internal {
+		t.Errorf("handled error expect %v, actual %v", internal, handled)
+	}
+	if len(recorded) != 1 || recorded[0] != internal {
+		t.Errorf("recorded errors expect [%v], actual %v", internal, recorded)
+	}
+}
+
+func TestHTTPError(t *testing.T) {
+	internal := errors.New("connection refused")
+	err := NewHTTPError(http.StatusServiceUnavailable).WithInternal(internal)
+
+	if msg := err.Error(); msg != "code=503, message=Service Unavailable, error=connection refused" {
+		t.Errorf("error message expect %q, actual %q", "code=503, message=Service Unavailable, error=connection refused", msg)
+	}
+	if !errors.Is(err, internal) {
+		t.Errorf("errors.Is expect true, actual false")
+	}
+}
@@ -0,0 +1,65 @@
+// Package aead provides the AES-GCM encryption with the key rotation, it's shared by the
+// encrypted cookies and the cookie session store.
+package aead
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+)
+
+// Cipher encrypts the data with the first key, and decrypts the data with all keys.
+type Cipher struct {
+	aeads []cipher.AEAD
+}
+
+// New creates a cipher with the AES keys, the keys should be 16, 24, or 32 bytes.
+func New(keys ...[]byte) (*Cipher, error) {
+	c := &Cipher{aeads: make([]cipher.AEAD, 0, len(keys))}
+
+	for _, key := range keys {
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+
+		c.aeads = append(c.aeads, aead)
+	}
+
+	return c, nil
+}
+
+// Seal encrypts and authenticates the plaintext and the additional data with the first key, and
+// returns the random nonce followed by the ciphertext.
+func (c *Cipher) Seal(plaintext, additionalData []byte) ([]byte, error) {
+	aead := c.aeads[0]
+
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// Open decrypts and authenticates the data that sealed by Seal with any key, it reports false if
+// the data can't be decrypted by the keys.
+func (c *Cipher) Open(data, additionalData []byte) ([]byte, bool) {
+	for _, aead := range c.aeads {
+		if len(data) < aead.NonceSize() {
+			continue
+		}
+
+		nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+		if plaintext, err := aead.Open(nil, nonce, ciphertext, additionalData); err == nil {
+			return plaintext, true
+		}
+	}
+
+	return nil, false
+}
@@ -0,0 +1,43 @@
+// Package dolphintest provides the helpers to test the middleware packages.
+package dolphintest
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/ghosind/dolphin"
+)
+
+// Request makes the request to a new app with the handlers, and returns the recorded response.
+func Request(req *http.Request, handlers ...dolphin.HandlerFunc) *httptest.ResponseRecorder {
+	app := dolphin.New(nil)
+	app.Use(handlers...)
+
+	return Serve(app, req)
+}
+
+// Serve makes the request to the app, and returns the recorded response.
+func Serve(app *dolphin.App, req *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, req)
+
+	return rec
+}
+
+// Capture makes the request through the middleware to a handler that responds "OK", and returns
+// the recorded response and the value that got by the handler. The value is the zero value if
+// the handler is not reached.
+func Capture[T any](
+	req *http.Request,
+	middleware dolphin.HandlerFunc,
+	get func(ctx *dolphin.Context) T,
+) (*httptest.ResponseRecorder, T) {
+	var val T
+
+	rec := Request(req, middleware, func(ctx *dolphin.Context) {
+		val = get(ctx)
+		ctx.String("OK")
+	})
+
+	return rec, val
+}
@@ -0,0 +1,203 @@
+package dolphin
+
+import (
+	"errors"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// unixAddrPrefix is the prefix of the Unix domain socket addresses, like "unix:/run/app.sock".
+const unixAddrPrefix = "unix:"
+
+// systemdAddrPrefix is the prefix of the systemd socket activation addresses, like "systemd:"
+// or "systemd:http".
+const systemdAddrPrefix = "systemd:"
+
+// systemdListenFdsStart is the first file descriptor passed by systemd.
+const systemdListenFdsStart = 3
+
+// Listen starts the app and listens on the address, it returns the error if the app can't be
+// started or the server fails, or nil after the app is shut down. The address can be:
+//
+//   - "host:port" or ":port" for TCP, like "127.0.0.1:8080".
+//   - "unix:/path/to/app.sock" for the Unix domain socket, the stale socket file is removed
+//     before listening, and the file mode is set to Config.UnixSocketMode if it's set.
+//   - "systemd:" or "systemd:name" for the first socket or the socket with the name
+//     (FileDescriptorName) that passed by the systemd socket activation.
+//
+// The port setting of the app is used if the address is empty.
+func (app *App) Listen(addr string) error {
+	ln, err := app.start(func() (net.Listener, error) {
+		if addr == "" {
+			return app.listenPort()
+		}
+		return listen(addr, app.unixSocketMode)
+	})
+	if err != nil {
+		return err
+	}
+
+	return app.serve(ln)
+}
+
+// Serve starts the app and accepts the connections on the listener, it returns the error if the
+// app can't be started or the server fails, or nil after the app is shut down. The listener is
+// closed when Serve returns.
+func (app *App) Serve(ln net.Listener) error {
+	_, err := app.start(func() (net.Listener, error) {
+		return ln, nil
+	})
+	if err != nil {
+		ln.Close()
+		return err
+	}
+
+	return app.serve(ln)
+}
+
+// SystemdListeners returns the listeners that passed by the systemd socket activation, or nil if
+// the process is not activated by systemd. The environment variables of the activation are
+// unset, so it should be called only once.
+func SystemdListeners() ([]net.Listener, error) {
+	files, err := systemdFiles()
+	if err != nil || files == nil {
+		return nil, err
+	}
+
+	listeners := make([]net.Listener, 0, len(files))
+	for _, file := range files {
+		ln, err := net.FileListener(file)
+		file.Close()
+		if err != nil {
+			for _, l := range listeners {
+				l.Close()
+			}
+			return nil, err
+		}
+		listeners = append(listeners, ln)
+	}
+
+	return listeners, nil
+}
+
+// listen creates the listener of the address.
+func listen(addr string, unixSocketMode os.FileMode) (net.Listener, error) {
+	switch {
+	case strings.HasPrefix(addr, unixAddrPrefix):
+		return listenUnix(strings.TrimPrefix(addr, unixAddrPrefix), unixSocketMode)
+	case strings.HasPrefix(addr, systemdAddrPrefix):
+		return listenSystemd(strings.TrimPrefix(addr, systemdAddrPrefix))
+	default:
+		return net.Listen("tcp", addr)
+	}
+}
+
+// listenUnix listens on the Unix domain socket, it removes the socket file if it's not used by
+// other processes, and sets the file mode if it's not zero.
+func listenUnix(path string, mode os.FileMode) (net.Listener, error) {
+	if path == "" {
+		return nil, ErrInvalidListenAddr
+	}
+
+	if err := removeStaleSocket(path); err != nil {
+		return nil, err
+	}
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+
+	if mode != 0 {
+		if err := os.Chmod(path, mode); err != nil {
+			ln.Close()
+			return nil, err
+		}
+	}
+
+	return ln, nil
+}
+
+// removeStaleSocket removes the socket file that left by the exited process. It returns
+// ErrSocketInUse if the socket is accepting connections, or ErrNotSocket if the file is not a
+// socket.
+func removeStaleSocket(path string) error {
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if info.Mode()&os.ModeSocket == 0 {
+		return ErrNotSocket
+	}
+
+	conn, err := net.DialTimeout("unix", path, time.Second)
+	if err == nil {
+		conn.Close()
+		return ErrSocketInUse
+	}
+
+	return os.Remove(path)
+}
+
+// listenSystemd returns the listener of the first socket or the socket with the name that passed
+// by the systemd socket activation, the other sockets are closed.
+func listenSystemd(name string) (net.Listener, error) {
+	files, err := systemdFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	var ln net.Listener
+	for _, file := range files {
+		if ln == nil && err == nil && (name == "" || file.Name() == name) {
+			ln, err = net.FileListener(file)
+		}
+		file.Close()
+	}
+
+	if err != nil {
+		return nil, err
+	} else if ln == nil {
+		return nil, ErrNoSystemdListener
+	}
+
+	return ln, nil
+}
+
+// systemdFiles returns the files of the sockets that passed by the systemd socket activation by
+// the environment variables "LISTEN_PID", "LISTEN_FDS", and "LISTEN_FDNAMES", and unsets them.
+func systemdFiles() ([]*os.File, error) {
+	pid := os.Getenv("LISTEN_PID")
+	fds := os.Getenv("LISTEN_FDS")
+	names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+
+	if fds == "" || (pid != "" && pid != strconv.Itoa(os.Getpid())) {
+		return nil, nil
+	}
+
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_FDNAMES")
+
+	n, err := strconv.Atoi(fds)
+	if err != nil || n < 0 {
+		return nil, ErrInvalidListenFds
+	}
+
+	files := make([]*os.File, 0, n)
+	for i := 0; i < n; i++ {
+		name := "unknown"
+		if i < len(names) && names[i] != "" {
+			name = names[i]
+		}
+		files = append(files, os.NewFile(uintptr(systemdListenFdsStart+i), name))
+	}
+
+	return files, nil
+}
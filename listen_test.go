@@ -0,0 +1,189 @@
+package dolphin
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestAppListenUnix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.sock")
+
+	app := New(&Config{UnixSocketMode: 0600})
+	app.Use(func(ctx *Context) {
+		ctx.String("hello")
+	})
+
+	ready := make(chan net.Addr, 1)
+	app.OnReady(func(ctx context.Context, addr net.Addr) error {
+		ready <- addr
+		return nil
+	})
+
+	result := make(chan error, 1)
+	go func() {
+		result <- app.Listen("unix:" + path)
+	}()
+
+	select {
+	case addr := <-ready:
+		if addr.Network() != "unix" || addr.String() != path {
+			t.Errorf("Ready address expect unix %s, actual %s %s", path, addr.Network(), addr)
+		}
+	case err := <-result:
+		t.Fatalf("Listen expect no error, actual %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat socket expect no error, actual %v", err)
+	}
+	if mode := info.Mode().Perm(); mode != 0600 {
+		t.Errorf("Socket mode expect 0600, actual %o", mode)
+	}
+
+	cli := http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+				return net.Dial("unix", path)
+			},
+		},
+	}
+	resp, err := cli.Get("http://dolphin/")
+	if err != nil {
+		t.Fatalf("GET expect no error, actual %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "hello" {
+		t.Errorf("Body expect \"hello\", actual %q", body)
+	}
+
+	if err := app.Listen("unix:" + path); err != ErrSocketInUse {
+		t.Errorf("Listen on used socket expect %v, actual %v", ErrSocketInUse, err)
+	}
+
+	if err := app.Shutdown(); err != nil {
+		t.Errorf("Shutdown expect no error, actual %v", err)
+	}
+	if err := <-result; err != nil {
+		t.Errorf("Listen expect nil after shutdown, actual %v", err)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Socket file expect removed, actual %v", err)
+	}
+}
+
+func TestListenUnixStaleSocket(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.sock")
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("Listen expect no error, actual %v", err)
+	}
+	ln.(*net.UnixListener).SetUnlinkOnClose(false)
+	ln.Close()
+
+	ln, err = listen("unix:"+path, 0)
+	if err != nil {
+		t.Fatalf("Listen on stale socket expect no error, actual %v", err)
+	}
+	ln.Close()
+
+	file := filepath.Join(dir, "app.txt")
+	if err := os.WriteFile(file, []byte("data"), 0600); err != nil {
+		t.Fatalf("WriteFile expect no error, actual %v", err)
+	}
+	if _, err := listen("unix:"+file, 0); err != ErrNotSocket {
+		t.Errorf("Listen on regular file expect %v, actual %v", ErrNotSocket, err)
+	}
+	if _, err := listen("unix:", 0); err != ErrInvalidListenAddr {
+		t.Errorf("Listen on empty path expect %v, actual %v", ErrInvalidListenAddr, err)
+	}
+}
+
+func TestAppListenTCP(t *testing.T) {
+	app := New(nil)
+	app.OnStart(func(ctx context.Context) error {
+		return nil
+	})
+
+	addr := testListen(t).Addr().String()
+	if err := app.Listen(addr); err == nil {
+		t.Error("Listen on used address expect error, actual nil")
+	}
+}
+
+func TestAppServe(t *testing.T) {
+	ln := testListen(t)
+
+	hookErr := errors.New("hook failed")
+	app := New(nil)
+	app.OnStart(func(ctx context.Context) error {
+		return hookErr
+	})
+
+	if err := app.Serve(ln); !errors.Is(err, hookErr) {
+		t.Errorf("Serve expect hook error, actual %v", err)
+	}
+	if _, err := ln.Accept(); err == nil {
+		t.Error("Accept after Serve expect error, actual nil")
+	}
+}
+
+func TestSystemdListeners(t *testing.T) {
+	if os.Getenv("DOLPHIN_TEST_SYSTEMD") == "1" {
+		ln, err := listenSystemd("http")
+		if err != nil {
+			t.Fatalf("listenSystemd expect no error, actual %v", err)
+		}
+		defer ln.Close()
+
+		if addr := ln.Addr().String(); addr != os.Getenv("DOLPHIN_TEST_ADDR") {
+			t.Errorf("Listener address expect %s, actual %s", os.Getenv("DOLPHIN_TEST_ADDR"), addr)
+		}
+		if os.Getenv("LISTEN_FDS") != "" {
+			t.Error("LISTEN_FDS expect unset, actual set")
+		}
+		return
+	}
+
+	metrics := testListen(t)
+	defer metrics.Close()
+	ln := testListen(t)
+	defer ln.Close()
+
+	files := make([]*os.File, 0, 2)
+	for _, l := range []net.Listener{metrics, ln} {
+		file, err := l.(*net.TCPListener).File()
+		if err != nil {
+			t.Fatalf("File expect no error, actual %v", err)
+		}
+		defer file.Close()
+		files = append(files, file)
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestSystemdListeners$")
+	cmd.Env = append(os.Environ(),
+		"DOLPHIN_TEST_SYSTEMD=1",
+		"DOLPHIN_TEST_ADDR="+ln.Addr().String(),
+		"LISTEN_FDS=2",
+		"LISTEN_FDNAMES=metrics:http",
+	)
+	cmd.ExtraFiles = files
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Errorf("Systemd activated process expect no error, actual %v: %s", err, out)
+	}
+
+	os.Unsetenv("LISTEN_FDS")
+	if listeners, err := SystemdListeners(); err != nil || listeners != nil {
+		t.Errorf("SystemdListeners without activation expect nil, actual %v %v", listeners, err)
+	}
+}
@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"strings"
+
+	"github.com/ghosind/dolphin"
+)
+
+// APIKey returns a middleware that authenticates the requests by the API key in the header,
+// query string, or cookie. The keys are checked with the Keys map in constant time, and then
+// the validator if it's set.
+func APIKey(config ...APIKeyConfig) dolphin.HandlerFunc {
+	cfg := getAPIKeyConfig(config...)
+	lookups := parseLookups(cfg.Lookup)
+
+	return func(ctx *dolphin.Context) {
+		key := ""
+		for _, lookup := range lookups {
+			if key = lookup(ctx); key != "" {
+				break
+			}
+		}
+
+		if key != "" {
+			if name, found := lookupKey(cfg.Keys, key); found {
+				setPrincipal(ctx, &Principal{Scheme: SchemeAPIKey, Name: name})
+				ctx.Next()
+				return
+			}
+
+			if cfg.Validator != nil {
+				if data, valid := cfg.Validator(ctx, key); valid {
+					setPrincipal(ctx, &Principal{Scheme: SchemeAPIKey, Data: data})
+					ctx.Next()
+					return
+				}
+			}
+		}
+
+		cfg.Handler(ctx)
+		ctx.Abort()
+	}
+}
+
+// lookupKey returns the name of the API key.
+func lookupKey(keys map[string]string, key string) (string, bool) {
+	if len(keys) == 0 {
+		return "", false
+	}
+
+	return lookupSecret(keys, key)
+}
+
+// parseLookups parses the lookup strings to the functions that get the API key from the
+// request. It panics if the source is unknown.
+func parseLookups(lookups []string) []func(*dolphin.Context) string {
+	funcs := make([]func(*dolphin.Context) string, 0, len(lookups))
+
+	for _, lookup := range lookups {
+		source, name, ok := strings.Cut(lookup, ":")
+		if !ok || name == "" {
+			panic("auth: invalid API key lookup " + lookup)
+		}
+
+		switch strings.ToLower(source) {
+		case "header":
+			funcs = append(funcs, func(ctx *dolphin.Context) string {
+				return ctx.Header(name)
+			})
+		case "query":
+			funcs = append(funcs, func(ctx *dolphin.Context) string {
+				return ctx.Query(name)
+			})
+		case "cookie":
+			funcs = append(funcs, func(ctx *dolphin.Context) string {
+				cookie, err := ctx.Cookie(name)
+				if err != nil {
+					return ""
+				}
+				return cookie.Value
+			})
+		default:
+			panic("auth: unknown API key source " + source)
+		}
+	}
+
+	return funcs
+}
@@ -0,0 +1,79 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/ghosind/dolphin"
+)
+
+// principalKey is the key of the principal in the context state.
+const principalKey = "github.com/ghosind/dolphin/middleware/auth.principal"
+
+const (
+	// SchemeBasic is the scheme of the Basic authentication.
+	SchemeBasic = "Basic"
+	// SchemeBearer is the scheme of the Bearer token authentication.
+	SchemeBearer = "Bearer"
+	// SchemeAPIKey is the scheme of the API key authentication.
+	SchemeAPIKey = "APIKey"
+)
+
+// Principal is the authenticated principal of the request.
+type Principal struct {
+	// Scheme is the authentication scheme, like "Basic", "Bearer", or "APIKey".
+	Scheme string
+	// Name is the name of the principal, it's the username of the Basic authentication, or
+	// the name of the API key in the APIKeyConfig.Keys.
+	Name string
+	// Data is the data that returned by the validator.
+	Data any
+}
+
+// GetPrincipal returns the authenticated principal of the request, it returns false if the
+// request is not authenticated.
+func GetPrincipal(ctx *dolphin.Context) (*Principal, bool) {
+	val, ok := ctx.Get(principalKey)
+	if !ok {
+		return nil, false
+	}
+
+	principal, ok := val.(*Principal)
+
+	return principal, ok
+}
+
+// setPrincipal stores the principal in the context state.
+func setPrincipal(ctx *dolphin.Context, principal *Principal) {
+	ctx.Set(principalKey, principal)
+}
+
+// secureCompare compares two strings in constant time, the strings are hashed first so the
+// comparison doesn't leak the length.
+func secureCompare(a, b string) bool {
+	hashA := sha256.Sum256([]byte(a))
+	hashB := sha256.Sum256([]byte(b))
+
+	return subtle.ConstantTimeCompare(hashA[:], hashB[:]) == 1
+}
+
+// lookupSecret finds the value of the key in the map with constant time comparisons, so the
+// lookup time doesn't leak whether the key exists.
+func lookupSecret(secrets map[string]string, key string) (string, bool) {
+	found := false
+	value := ""
+
+	for k, v := range secrets {
+		if secureCompare(k, key) {
+			found = true
+			value = v
+		}
+	}
+
+	return value, found
+}
+
+func defaultHandler(ctx *dolphin.Context) {
+	ctx.String("Unauthorized", http.StatusUnauthorized)
+}
@@ -0,0 +1,114 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ghosind/dolphin"
+	"github.com/ghosind/dolphin/internal/dolphintest"
+)
+
+// getPrincipal returns the principal of the request, or nil if it is not authenticated.
+func getPrincipal(ctx *dolphin.Context) *Principal {
+	principal, _ := GetPrincipal(ctx)
+	return principal
+}
+
+func TestBasicAuth(t *testing.T) {
+	middleware := BasicAuth(BasicConfig{
+		Realm: "Admin",
+		Users: map[string]string{"admin": "secret"},
+		Validator: func(ctx *dolphin.Context, username, password string) (any, bool) {
+			return "validated", username == "guest" && password == "guest"
+		},
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.SetBasicAuth("admin", "secret")
+	rec, principal := dolphintest.Capture(req, middleware, getPrincipal)
+	if rec.Code != http.StatusOK || principal == nil || principal.Name != "admin" || principal.Scheme != SchemeBasic {
+		t.Errorf("Valid user expect authenticated, actual %d %+v", rec.Code, principal)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.SetBasicAuth("guest", "guest")
+	rec, principal = dolphintest.Capture(req, middleware, getPrincipal)
+	if rec.Code != http.StatusOK || principal == nil || principal.Data != "validated" {
+		t.Errorf("Validated user expect authenticated, actual %d %+v", rec.Code, principal)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.SetBasicAuth("admin", "wrong")
+	rec, _ = dolphintest.Capture(req, middleware, getPrincipal)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Invalid password expect 401, actual %d", rec.Code)
+	}
+	if challenge := rec.Header().Get("WWW-Authenticate"); challenge != `Basic realm="Admin", charset="UTF-8"` {
+		t.Errorf("Invalid password expect challenge, actual %q", challenge)
+	}
+
+	for _, password := range []string{"", dummyPassword} {
+		req = httptest.NewRequest(http.MethodGet, "/", nil)
+		req.SetBasicAuth("unknown", password)
+		if rec, _ = dolphintest.Capture(req, middleware, getPrincipal); rec.Code != http.StatusUnauthorized {
+			t.Errorf("Unknown user with password %q expect 401, actual %d", password, rec.Code)
+		}
+	}
+}
+
+func TestBearerAuth(t *testing.T) {
+	middleware := BearerAuth(BearerConfig{
+		Validator: func(ctx *dolphin.Context, token string) (any, bool) {
+			return "user-1", token == "valid-token"
+		},
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "bearer valid-token")
+	rec, principal := dolphintest.Capture(req, middleware, getPrincipal)
+	if rec.Code != http.StatusOK || principal == nil || principal.Data != "user-1" {
+		t.Errorf("Valid token expect authenticated, actual %d %+v", rec.Code, principal)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer invalid")
+	rec, _ = dolphintest.Capture(req, middleware, getPrincipal)
+	if rec.Code != http.StatusUnauthorized ||
+		rec.Header().Get("WWW-Authenticate") != `Bearer realm="Restricted", error="invalid_token"` {
+		t.Errorf("Invalid token expect 401 with error, actual %d %v", rec.Code, rec.Header())
+	}
+
+	rec, _ = dolphintest.Capture(httptest.NewRequest(http.MethodGet, "/", nil), middleware, getPrincipal)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Missing token expect 401, actual %d", rec.Code)
+	}
+}
+
+func TestAPIKey(t *testing.T) {
+	middleware := APIKey(APIKeyConfig{
+		Keys:   map[string]string{"key-1": "service-a"},
+		Lookup: []string{"header:X-API-Key", "query:api_key", "cookie:api_key"},
+	})
+
+	requests := []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/", nil),
+		httptest.NewRequest(http.MethodGet, "/?api_key=key-1", nil),
+		httptest.NewRequest(http.MethodGet, "/", nil),
+	}
+	requests[0].Header.Set("X-API-Key", "key-1")
+	requests[2].AddCookie(&http.Cookie{Name: "api_key", Value: "key-1"})
+
+	for i, req := range requests {
+		rec, principal := dolphintest.Capture(req, middleware, getPrincipal)
+		if rec.Code != http.StatusOK || principal == nil || principal.Name != "service-a" {
+			t.Errorf("Request %d expect authenticated, actual %d %+v", i, rec.Code, principal)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-API-Key", "key-2")
+	if rec, _ := dolphintest.Capture(req, middleware, getPrincipal); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Invalid key expect 401, actual %d", rec.Code)
+	}
+}
@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"strconv"
+
+	"github.com/ghosind/dolphin"
+)
+
+// dummyPassword is compared with the password of the unknown users.
+const dummyPassword = "github.com/ghosind/dolphin/middleware/auth.dummyPassword"
+
+// BasicAuth returns a middleware that authenticates the requests by the Basic authentication
+// scheme. The credentials are checked with the Users map in constant time, and then the
+// validator if it's set.
+func BasicAuth(config ...BasicConfig) dolphin.HandlerFunc {
+	cfg := getBasicConfig(config...)
+	challenge := "Basic realm=" + strconv.Quote(cfg.Realm) + `, charset="UTF-8"`
+
+	return func(ctx *dolphin.Context) {
+		username, password, ok := ctx.BasicAuth()
+		if ok {
+			expected, found := lookupUser(cfg.Users, username)
+			if !found {
+				// Compare with the dummy password, so the response time doesn't leak whether the
+				// user exists.
+				expected = dummyPassword
+			}
+			if matched := secureCompare(password, expected); found && matched {
+				setPrincipal(ctx, &Principal{Scheme: SchemeBasic, Name: username})
+				ctx.Next()
+				return
+			}
+
+			if cfg.Validator != nil {
+				if data, valid := cfg.Validator(ctx, username, password); valid {
+					setPrincipal(ctx, &Principal{Scheme: SchemeBasic, Name: username, Data: data})
+					ctx.Next()
+					return
+				}
+			}
+		}
+
+		ctx.SetHeader("WWW-Authenticate", challenge)
+		cfg.Handler(ctx)
+		ctx.Abort()
+	}
+}
+
+// lookupUser returns the password of the user.
+func lookupUser(users map[string]string, username string) (string, bool) {
+	if len(users) == 0 {
+		return "", false
+	}
+
+	return lookupSecret(users, username)
+}
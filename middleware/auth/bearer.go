@@ -0,0 +1,52 @@
+package auth
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/ghosind/dolphin"
+)
+
+// BearerAuth returns a middleware that authenticates the requests by the Bearer token in the
+// "Authorization" header. It panics if the validator is not set.
+func BearerAuth(config ...BearerConfig) dolphin.HandlerFunc {
+	cfg := getBearerConfig(config...)
+	if cfg.Validator == nil {
+		panic("auth: bearer token validator is required")
+	}
+
+	realm := "Bearer realm=" + strconv.Quote(cfg.Realm)
+
+	return func(ctx *dolphin.Context) {
+		token, ok := ExtractBearerToken(ctx)
+		if !ok {
+			ctx.SetHeader("WWW-Authenticate", realm)
+			cfg.Handler(ctx)
+			ctx.Abort()
+			return
+		}
+
+		data, valid := cfg.Validator(ctx, token)
+		if !valid {
+			ctx.SetHeader("WWW-Authenticate", realm+`, error="invalid_token"`)
+			cfg.Handler(ctx)
+			ctx.Abort()
+			return
+		}
+
+		setPrincipal(ctx, &Principal{Scheme: SchemeBearer, Data: data})
+		ctx.Next()
+	}
+}
+
+// ExtractBearerToken returns the Bearer token in the "Authorization" header of the request.
+func ExtractBearerToken(ctx *dolphin.Context) (string, bool) {
+	scheme, token, ok := strings.Cut(ctx.Header("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, SchemeBearer) {
+		return "", false
+	}
+
+	token = strings.TrimSpace(token)
+
+	return token, token != ""
+}
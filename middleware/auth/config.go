@@ -0,0 +1,116 @@
+package auth
+
+import "github.com/ghosind/dolphin"
+
+// Validator validates the credential of the request, and returns the data of the principal
+// and true if the credential is valid. The data will be stored in the Principal.Data field.
+type Validator func(ctx *dolphin.Context, credential string) (any, bool)
+
+// BasicValidator validates the username and password of the Basic authentication, and returns
+// the data of the principal and true if they're valid.
+type BasicValidator func(ctx *dolphin.Context, username, password string) (any, bool)
+
+// BasicConfig is the Basic authentication middleware config.
+type BasicConfig struct {
+	// Handler is the handler that will be triggered when the authentication is failed, it'll
+	// return a 401 (Unauthorized) response if the handler is not set.
+	Handler dolphin.HandlerFunc
+	// Realm is the protection space of the authentication, default "Restricted".
+	Realm string
+	// Users is the map of the usernames and passwords, it's checked before the validator.
+	Users map[string]string
+	// Validator validates the credentials that not matched by the Users.
+	Validator BasicValidator
+}
+
+// BearerConfig is the Bearer token authentication middleware config.
+type BearerConfig struct {
+	// Handler is the handler that will be triggered when the authentication is failed, it'll
+	// return a 401 (Unauthorized) response if the handler is not set.
+	Handler dolphin.HandlerFunc
+	// Realm is the protection space of the authentication, default "Restricted".
+	Realm string
+	// Validator validates the bearer token, it's required.
+	Validator Validator
+}
+
+// APIKeyConfig is the API key authentication middleware config.
+type APIKeyConfig struct {
+	// Handler is the handler that will be triggered when the authentication is failed, it'll
+	// return a 401 (Unauthorized) response if the handler is not set.
+	Handler dolphin.HandlerFunc
+	// Keys is the map of the valid API keys and their names, it's checked before the
+	// validator.
+	Keys map[string]string
+	// Lookup is the list of the places to look up the API key in the form of "<source>:<name>",
+	// the source can be "header", "query", or "cookie". Default "header:X-API-Key".
+	Lookup []string
+	// Validator validates the API keys that not matched by the Keys.
+	Validator Validator
+}
+
+// DefaultBasicConfig is the default Basic authentication middleware config.
+var DefaultBasicConfig = BasicConfig{
+	Handler: defaultHandler,
+	Realm:   "Restricted",
+}
+
+// DefaultBearerConfig is the default Bearer token authentication middleware config.
+var DefaultBearerConfig = BearerConfig{
+	Handler: defaultHandler,
+	Realm:   "Restricted",
+}
+
+// DefaultAPIKeyConfig is the default API key authentication middleware config.
+var DefaultAPIKeyConfig = APIKeyConfig{
+	Handler: defaultHandler,
+	Lookup:  []string{"header:X-API-Key"},
+}
+
+func getBasicConfig(config ...BasicConfig) BasicConfig {
+	if len(config) < 1 {
+		return DefaultBasicConfig
+	}
+
+	cfg := config[0]
+	if cfg.Handler == nil {
+		cfg.Handler = DefaultBasicConfig.Handler
+	}
+	if cfg.Realm == "" {
+		cfg.Realm = DefaultBasicConfig.Realm
+	}
+
+	return cfg
+}
+
+func getBearerConfig(config ...BearerConfig) BearerConfig {
+	if len(config) < 1 {
+		return DefaultBearerConfig
+	}
+
+	cfg := config[0]
+	if cfg.Handler == nil {
+		cfg.Handler = DefaultBearerConfig.Handler
+	}
+	if cfg.Realm == "" {
+		cfg.Realm = DefaultBearerConfig.Realm
+	}
+
+	return cfg
+}
+
+func getAPIKeyConfig(config ...APIKeyConfig) APIKeyConfig {
+	if len(config) < 1 {
+		return DefaultAPIKeyConfig
+	}
+
+	cfg := config[0]
+	if cfg.Handler == nil {
+		cfg.Handler = DefaultAPIKeyConfig.Handler
+	}
+	if len(cfg.Lookup) == 0 {
+		cfg.Lookup = DefaultAPIKeyConfig.Lookup
+	}
+
+	return cfg
+}
@@ -0,0 +1,265 @@
+package compress
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ghosind/dolphin"
+)
+
+const (
+	encodingGzip    = "gzip"
+	encodingDeflate = "deflate"
+)
+
+// compressor is the interface of the pooled gzip and flate writers.
+type compressor interface {
+	io.WriteCloser
+	Reset(w io.Writer)
+}
+
+// streamReader is the reader of the compressed streaming body, it starts compressing at the first
+// read, and closes the pipe and the source stream when the response is finished.
+type streamReader struct {
+	*io.PipeReader
+	compress func()
+	once     sync.Once
+	source   io.Reader
+}
+
+// Compress returns a middleware that compresses the response body with gzip or deflate by
+// the "Accept-Encoding" request header. It works with both the buffered and the streaming
+// responses, and skips the responses that are already encoded, ranged, or not in the
+// compressible content types.
+func Compress(config ...Config) dolphin.HandlerFunc {
+	cfg := getConfig(config...)
+
+	pools := map[string]*sync.Pool{
+		encodingGzip: {
+			New: func() any {
+				w, err := gzip.NewWriterLevel(io.Discard, cfg.Level)
+				if err != nil {
+					w = gzip.NewWriter(io.Discard)
+				}
+				return w
+			},
+		},
+		encodingDeflate: {
+			New: func() any {
+				w, err := flate.NewWriter(io.Discard, cfg.Level)
+				if err != nil {
+					w, _ = flate.NewWriter(io.Discard, flate.DefaultCompression)
+				}
+				return w
+			},
+		},
+	}
+
+	return func(ctx *dolphin.Context) {
+		ctx.Next()
+
+		resp := ctx.Response
+		if !isCompressible(ctx, &cfg) {
+			return
+		}
+		resp.AddHeader("Vary", "Accept-Encoding")
+
+		encoding := negotiateEncoding(ctx.MultiValuesHeader("Accept-Encoding"))
+		if encoding == "" {
+			return
+		}
+		pool := pools[encoding]
+
+		if stream := resp.Stream(); stream != nil {
+			if length, err := strconv.Atoi(resp.Header("Content-Length")); err == nil &&
+				length+len(resp.Body()) < cfg.MinLength {
+				return
+			}
+
+			// Detach the stream before discarding the buffered body, so it'll not be closed.
+			body := append([]byte(nil), resp.Body()...)
+			resp.SetStream(nil)
+			resp.ResetBody()
+
+			reader := io.MultiReader(bytes.NewReader(body), stream)
+			resp.SetStream(compressStream(reader, stream, pool))
+		} else {
+			body := resp.Body()
+			if len(body) < cfg.MinLength {
+				return
+			}
+
+			compressed, err := compressBody(body, pool)
+			if err != nil {
+				ctx.Log("Failed to compress response: %v\n", err)
+				return
+			}
+
+			resp.ResetBody()
+			resp.SetBody(compressed)
+		}
+
+		resp.SetHeader("Content-Encoding", encoding)
+		resp.DelHeader("Content-Length")
+
+		// The compressed representation is different from the original one, so the strong
+		// entity tag should be converted to a weak entity tag.
+		if etag := resp.Header("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+			resp.SetHeader("ETag", "W/"+etag)
+		}
+	}
+}
+
+// isCompressible reports whether the response can be compressed.
+func isCompressible(ctx *dolphin.Context, cfg *Config) bool {
+	resp := ctx.Response
+
+	switch resp.StatusCode() {
+	case http.StatusNoContent, http.StatusPartialContent, http.StatusNotModified:
+		return false
+	}
+
+	if ctx.Method() == http.MethodHead ||
+		resp.Header("Content-Encoding") != "" ||
+		resp.Header("Content-Range") != "" ||
+		strings.Contains(resp.Header("Cache-Control"), "no-transform") {
+		return false
+	}
+
+	contentType := strings.ToLower(resp.Header("Content-Type"))
+	if i := strings.IndexByte(contentType, ';'); i >= 0 {
+		contentType = contentType[:i]
+	}
+	contentType = strings.TrimSpace(contentType)
+	if contentType == "" {
+		return false
+	}
+
+	for _, allowed := range cfg.ContentTypes {
+		if strings.HasSuffix(allowed, "/") && strings.HasPrefix(contentType, allowed) ||
+			contentType == allowed {
+			return true
+		}
+	}
+
+	return false
+}
+
+// negotiateEncoding returns the preferred supported encoding of the client by the
+// "Accept-Encoding" header values, it returns an empty string if no encoding is acceptable.
+func negotiateEncoding(headers []string) string {
+	qualities := make(map[string]float64)
+	wildcard := -1.0
+
+	for _, header := range headers {
+		for _, item := range strings.Split(header, ",") {
+			coding, params, _ := strings.Cut(item, ";")
+			coding = strings.ToLower(strings.TrimSpace(coding))
+			if coding == "" {
+				continue
+			}
+			if coding == "x-gzip" {
+				coding = encodingGzip
+			}
+
+			q := 1.0
+			params = strings.TrimSpace(params)
+			if strings.HasPrefix(params, "q=") {
+				if v, err := strconv.ParseFloat(params[2:], 64); err == nil {
+					q = v
+				}
+			}
+
+			if coding == "*" {
+				wildcard = q
+			} else {
+				qualities[coding] = q
+			}
+		}
+	}
+
+	encoding := ""
+	best := 0.0
+	for _, coding := range []string{encodingGzip, encodingDeflate} {
+		q, ok := qualities[coding]
+		if !ok {
+			q = wildcard
+		}
+
+		if q > best {
+			encoding, best = coding, q
+		}
+	}
+
+	return encoding
+}
+
+// compressBody compresses the buffered body with the pooled writer.
+func compressBody(body []byte, pool *sync.Pool) ([]byte, error) {
+	buf := new(bytes.Buffer)
+
+	w := pool.Get().(compressor)
+	defer pool.Put(w)
+	w.Reset(buf)
+
+	if _, err := w.Write(body); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// compressStream returns a reader that compresses the content of the reader in a background
+// goroutine. The goroutine is started at the first read, and it stops when the returned reader
+// is closed, so it'll not be leaked if the stream is discarded or not read to the end.
+func compressStream(reader, source io.Reader, pool *sync.Pool) io.ReadCloser {
+	pr, pw := io.Pipe()
+
+	compress := func() {
+		go func() {
+			w := pool.Get().(compressor)
+			defer pool.Put(w)
+			w.Reset(pw)
+
+			_, err := io.Copy(w, reader)
+			if closeErr := w.Close(); err == nil {
+				err = closeErr
+			}
+
+			pw.CloseWithError(err)
+		}()
+	}
+
+	return &streamReader{PipeReader: pr, compress: compress, source: source}
+}
+
+// Read starts compressing at the first call, and reads the compressed content.
+func (r *streamReader) Read(p []byte) (int, error) {
+	r.once.Do(r.compress)
+
+	return r.PipeReader.Read(p)
+}
+
+// Close closes the pipe and the source stream, the compressing will not be started after it's
+// closed.
+func (r *streamReader) Close() error {
+	r.once.Do(func() {})
+	err := r.PipeReader.Close()
+
+	if closer, ok := r.source.(io.Closer); ok {
+		if closeErr := closer.Close(); err == nil {
+			err = closeErr
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,156 @@
+package compress
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ghosind/dolphin"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	cases := map[string]string{
+		"":                          "",
+		"gzip":                      "gzip",
+		"deflate":                   "deflate",
+		"gzip, deflate, br":         "gzip",
+		"gzip;q=0.5, deflate":       "deflate",
+		"gzip;q=0, *":               "deflate",
+		"*;q=0":                     "",
+		"br":                        "",
+		"identity, x-gzip;q=0.1":    "gzip",
+		"GZIP;q=0.8, DEFLATE;q=0.9": "deflate",
+	}
+
+	for header, expected := range cases {
+		if actual := negotiateEncoding([]string{header}); actual != expected {
+			t.Errorf("negotiateEncoding(%q) expect %q, actual %q", header, expected, actual)
+		}
+	}
+}
+
+func TestCompress(t *testing.T) {
+	body := strings.Repeat("dolphin ", 200)
+
+	app := dolphin.New(nil)
+	app.Use(Compress(), func(ctx *dolphin.Context) {
+		switch ctx.Path() {
+		case "/small":
+			ctx.String("small")
+		case "/binary":
+			ctx.Blob("image/png", []byte(body))
+		case "/stream":
+			ctx.Stream("text/plain", io.NopCloser(strings.NewReader(body)))
+		default:
+			ctx.String(body)
+		}
+	})
+
+	cases := []struct {
+		path       string
+		compressed bool
+	}{
+		{"/", true},
+		{"/stream", true},
+		{"/small", false},
+		{"/binary", false},
+	}
+
+	for _, c := range cases {
+		req := httptest.NewRequest(http.MethodGet, c.path, nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		rec := httptest.NewRecorder()
+		app.ServeHTTP(rec, req)
+
+		encoding := rec.Header().Get("Content-Encoding")
+		if (encoding == "gzip") != c.compressed {
+			t.Errorf("GET %s expect compressed %v, actual Content-Encoding %q", c.path, c.compressed, encoding)
+			continue
+		}
+		if !c.compressed {
+			continue
+		}
+
+		if vary := rec.Header().Get("Vary"); vary != "Accept-Encoding" {
+			t.Errorf("GET %s expect Vary \"Accept-Encoding\", actual %q", c.path, vary)
+		}
+
+		reader, err := gzip.NewReader(rec.Body)
+		if err != nil {
+			t.Errorf("GET %s expect valid gzip body, actual error %v", c.path, err)
+			continue
+		}
+		decoded, _ := io.ReadAll(reader)
+		if string(decoded) != body {
+			t.Errorf("GET %s expect decoded body equals to the original body", c.path)
+		}
+	}
+}
+
+func TestCompressLevel(t *testing.T) {
+	if cfg := getConfig(Config{}); cfg.Level != gzip.DefaultCompression {
+		t.Errorf("Unset level expect %d, actual %d", gzip.DefaultCompression, cfg.Level)
+	}
+
+	body := strings.Repeat("dolphin ", 200)
+
+	app := dolphin.New(nil)
+	app.Use(Compress(Config{Level: NoCompression}), func(ctx *dolphin.Context) {
+		ctx.String(body)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, req)
+
+	if rec.Body.Len() <= len(body) {
+		t.Errorf("Body without compression expect longer than %d, actual %d", len(body), rec.Body.Len())
+	}
+	reader, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatalf("Body expect valid gzip, actual error %v", err)
+	}
+	if decoded, _ := io.ReadAll(reader); string(decoded) != body {
+		t.Error("Decoded body expect equals to the original body")
+	}
+}
+
+// testSource is a streaming body that records the reads and the close.
+type testSource struct {
+	closed bool
+	reads  int
+}
+
+func (s *testSource) Read(p []byte) (int, error) {
+	s.reads++
+	return 0, io.EOF
+}
+
+func (s *testSource) Close() error {
+	s.closed = true
+	return nil
+}
+
+func TestCompressStreamClose(t *testing.T) {
+	pool := &sync.Pool{New: func() any { return gzip.NewWriter(io.Discard) }}
+	source := new(testSource)
+
+	stream := compressStream(source, source, pool)
+	if err := stream.Close(); err != nil {
+		t.Errorf("Close expect no error, actual %v", err)
+	}
+	if !source.closed {
+		t.Error("Source expect closed, actual not")
+	}
+	if _, err := stream.Read(make([]byte, 1)); err != io.ErrClosedPipe {
+		t.Errorf("Read after close expect %v, actual %v", io.ErrClosedPipe, err)
+	}
+	if source.reads != 0 {
+		t.Errorf("Source expect not read after close, actual %d reads", source.reads)
+	}
+}
@@ -0,0 +1,60 @@
+package compress
+
+import "compress/gzip"
+
+// Config is the compress middleware config.
+type Config struct {
+	// ContentTypes is the list of the compressible content types, a content type that ends
+	// with "/" matches all subtypes, like "text/". Default DefaultContentTypes.
+	ContentTypes []string
+	// Level is the compression level, it should be between -2 (Huffman only) and 9 (best
+	// compression), or NoCompression. It uses the default compression level if it's not set.
+	Level int
+	// MinLength is the minimum length of the buffered response body to compress, the smaller
+	// responses are sent without compression. Default 1024 bytes.
+	MinLength int
+}
+
+// NoCompression is the Level to write the encoded body without compression, like
+// gzip.NoCompression. The zero Level can't be used for it, because it means the level is not set.
+const NoCompression = -3
+
+// DefaultContentTypes is the default list of the compressible content types.
+var DefaultContentTypes = []string{
+	"text/",
+	"application/javascript",
+	"application/json",
+	"application/problem+json",
+	"application/xml",
+	"application/yaml",
+	"application/wasm",
+	"image/svg+xml",
+}
+
+// DefaultConfig is the default compress middleware config.
+var DefaultConfig = Config{
+	ContentTypes: DefaultContentTypes,
+	Level:        gzip.DefaultCompression,
+	MinLength:    1024,
+}
+
+func getConfig(config ...Config) Config {
+	if len(config) < 1 {
+		return DefaultConfig
+	}
+
+	cfg := config[0]
+	if cfg.ContentTypes == nil {
+		cfg.ContentTypes = DefaultContentTypes
+	}
+	if cfg.Level == 0 {
+		cfg.Level = DefaultConfig.Level
+	} else if cfg.Level == NoCompression {
+		cfg.Level = gzip.NoCompression
+	}
+	if cfg.MinLength <= 0 {
+		cfg.MinLength = DefaultConfig.MinLength
+	}
+
+	return cfg
+}
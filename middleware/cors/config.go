@@ -0,0 +1,78 @@
+package cors
+
+import (
+	"net/http"
+	"regexp"
+	"time"
+)
+
+// Config is the CORS middleware config.
+type Config struct {
+	// AllowCredentials indicates whether the response can be shared with the requests that
+	// include credentials like cookies. It can't be used with the "*" origin, the allowed origins
+	// should be listed explicitly.
+	AllowCredentials bool
+	// AllowHeaders is the list of the request headers that can be used in the actual request,
+	// the headers in the preflight request's "Access-Control-Request-Headers" will be allowed
+	// if it's not set.
+	AllowHeaders []string
+	// AllowMethods is the list of the methods that allowed to access the resources, default
+	// GET, HEAD, PUT, PATCH, POST, and DELETE.
+	AllowMethods []string
+	// AllowOriginFunc is the function to validate the request origin, it'll be called if the
+	// origin is not matched by AllowOrigins and AllowOriginPatterns.
+	AllowOriginFunc func(origin string) bool
+	// AllowOriginPatterns is the list of the regular expressions to match the request origin.
+	AllowOriginPatterns []*regexp.Regexp
+	// AllowOrigins is the list of the origins that allowed to access the resources. An origin
+	// can be "*" to allow all origins, an exact origin like "https://example.com", or an
+	// origin with a wildcard subdomain like "https://*.example.com". Default "*" if none of
+	// AllowOrigins, AllowOriginPatterns, and AllowOriginFunc are set.
+	AllowOrigins []string
+	// AllowPrivateNetwork indicates whether the resources can be accessed from the public
+	// network by the Private Network Access preflight requests.
+	AllowPrivateNetwork bool
+	// ExposeHeaders is the list of the response headers that can be accessed by the client
+	// scripts.
+	ExposeHeaders []string
+	// MaxAge is the duration that the preflight results can be cached, the
+	// "Access-Control-Max-Age" header will not be set if it's zero.
+	MaxAge time.Duration
+}
+
+// DefaultConfig is the default CORS middleware config.
+var DefaultConfig = Config{
+	AllowMethods: []string{
+		http.MethodGet,
+		http.MethodHead,
+		http.MethodPut,
+		http.MethodPatch,
+		http.MethodPost,
+		http.MethodDelete,
+	},
+	AllowOrigins: []string{"*"},
+}
+
+func getConfig(config ...Config) Config {
+	if len(config) < 1 {
+		return DefaultConfig
+	}
+
+	cfg := config[0]
+	if len(cfg.AllowMethods) == 0 {
+		cfg.AllowMethods = DefaultConfig.AllowMethods
+	}
+	if len(cfg.AllowOrigins) == 0 && len(cfg.AllowOriginPatterns) == 0 && cfg.AllowOriginFunc == nil {
+		cfg.AllowOrigins = DefaultConfig.AllowOrigins
+	}
+
+	if cfg.AllowCredentials {
+		for _, origin := range cfg.AllowOrigins {
+			if origin == "*" {
+				panic("cors: the \"*\" origin can't be used with AllowCredentials")
+			}
+		}
+	}
+
+	return cfg
+}
@@ -0,0 +1,171 @@
+package cors
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ghosind/dolphin"
+)
+
+// wildcardOrigin is an allowed origin with a wildcard subdomain, like "https://*.example.com".
+type wildcardOrigin struct {
+	prefix string
+	suffix string
+}
+
+// originMatcher validates the request origins by the config.
+type originMatcher struct {
+	allowAll  bool
+	exact     map[string]struct{}
+	wildcards []wildcardOrigin
+	cfg       *Config
+}
+
+// CORS returns a middleware that handles the Cross-Origin Resource Sharing requests. It sets
+// the CORS response headers for the allowed origins, and responds the preflight requests with
+// 204 (No Content) directly, so the OPTIONS routes are not required for the preflight requests.
+// It panics if AllowCredentials is enabled with the "*" origin, including the default origins.
+func CORS(config ...Config) dolphin.HandlerFunc {
+	cfg := getConfig(config...)
+	matcher := newOriginMatcher(&cfg)
+
+	allowMethods := strings.Join(cfg.AllowMethods, ", ")
+	allowHeaders := strings.Join(cfg.AllowHeaders, ", ")
+	exposeHeaders := strings.Join(cfg.ExposeHeaders, ", ")
+	maxAge := ""
+	if cfg.MaxAge > 0 {
+		maxAge = strconv.FormatInt(int64(cfg.MaxAge.Seconds()), 10)
+	}
+
+	return func(ctx *dolphin.Context) {
+		origin := ctx.Header("Origin")
+		isPreflight := ctx.Method() == http.MethodOptions && origin != "" &&
+			ctx.Header("Access-Control-Request-Method") != ""
+
+		// The response varies by the origin unless all origins are allowed.
+		if !matcher.allowAll {
+			ctx.AddHeader("Vary", "Origin")
+		}
+		if isPreflight {
+			ctx.AddHeader("Vary", "Access-Control-Request-Method")
+			ctx.AddHeader("Vary", "Access-Control-Request-Headers")
+			if cfg.AllowPrivateNetwork {
+				ctx.AddHeader("Vary", "Access-Control-Request-Private-Network")
+			}
+		}
+
+		if origin == "" || !matcher.match(origin) {
+			if isPreflight {
+				ctx.SetStatusCode(http.StatusNoContent)
+				ctx.Abort()
+				return
+			}
+
+			ctx.Next()
+			return
+		}
+
+		if matcher.allowAll {
+			ctx.SetHeader("Access-Control-Allow-Origin", "*")
+		} else {
+			ctx.SetHeader("Access-Control-Allow-Origin", origin)
+		}
+		if cfg.AllowCredentials {
+			ctx.SetHeader("Access-Control-Allow-Credentials", "true")
+		}
+
+		if !isPreflight {
+			if exposeHeaders != "" {
+				ctx.SetHeader("Access-Control-Expose-Headers", exposeHeaders)
+			}
+
+			ctx.Next()
+			return
+		}
+
+		ctx.SetHeader("Access-Control-Allow-Methods", allowMethods)
+		if allowHeaders != "" {
+			ctx.SetHeader("Access-Control-Allow-Headers", allowHeaders)
+		} else if requestHeaders := ctx.Header("Access-Control-Request-Headers"); requestHeaders != "" {
+			ctx.SetHeader("Access-Control-Allow-Headers", requestHeaders)
+		}
+		if maxAge != "" {
+			ctx.SetHeader("Access-Control-Max-Age", maxAge)
+		}
+		if cfg.AllowPrivateNetwork && ctx.Header("Access-Control-Request-Private-Network") == "true" {
+			ctx.SetHeader("Access-Control-Allow-Private-Network", "true")
+		}
+
+		ctx.SetStatusCode(http.StatusNoContent)
+		ctx.Abort()
+	}
+}
+
+// newOriginMatcher creates an origin matcher by the config.
+func newOriginMatcher(cfg *Config) *originMatcher {
+	matcher := &originMatcher{
+		exact: make(map[string]struct{}),
+		cfg:   cfg,
+	}
+
+	for _, origin := range cfg.AllowOrigins {
+		origin = strings.ToLower(origin)
+
+		if origin == "*" {
+			matcher.allowAll = true
+		} else if prefix, suffix, ok := strings.Cut(origin, "*"); ok {
+			matcher.wildcards = append(matcher.wildcards, wildcardOrigin{
+				prefix: prefix,
+				suffix: suffix,
+			})
+		} else {
+			matcher.exact[origin] = struct{}{}
+		}
+	}
+
+	return matcher
+}
+
+// match reports whether the origin is allowed.
+func (matcher *originMatcher) match(origin string) bool {
+	if matcher.allowAll {
+		return true
+	}
+
+	lowerOrigin := strings.ToLower(origin)
+	if _, ok := matcher.exact[lowerOrigin]; ok {
+		return true
+	}
+
+	for _, wildcard := range matcher.wildcards {
+		if wildcard.match(lowerOrigin) {
+			return true
+		}
+	}
+
+	for _, pattern := range matcher.cfg.AllowOriginPatterns {
+		if pattern.MatchString(origin) {
+			return true
+		}
+	}
+
+	if matcher.cfg.AllowOriginFunc != nil {
+		return matcher.cfg.AllowOriginFunc(origin)
+	}
+
+	return false
+}
+
+// match reports whether the origin matches the wildcard origin, the wildcard only matches
+// the subdomains.
+func (wildcard wildcardOrigin) match(origin string) bool {
+	if len(origin) <= len(wildcard.prefix)+len(wildcard.suffix) ||
+		!strings.HasPrefix(origin, wildcard.prefix) || !strings.HasSuffix(origin, wildcard.suffix) {
+		return false
+	}
+
+	subdomain := origin[len(wildcard.prefix) : len(origin)-len(wildcard.suffix)]
+
+	return !strings.ContainsAny(subdomain, "/:@?#")
+}
@@ -0,0 +1,111 @@
+package cors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/ghosind/dolphin"
+)
+
+func TestCORSOrigins(t *testing.T) {
+	app := dolphin.New(nil)
+	app.Use(CORS(Config{
+		AllowOrigins:        []string{"https://example.com", "https://*.example.org"},
+		AllowOriginPatterns: []*regexp.Regexp{regexp.MustCompile(`^http://localhost:\d+$`)},
+		AllowOriginFunc:     func(origin string) bool { return origin == "https://partner.test" },
+		ExposeHeaders:       []string{"X-Total-Count"},
+	}), func(ctx *dolphin.Context) {
+		ctx.String("OK")
+	})
+
+	cases := map[string]bool{
+		"https://example.com":      true,
+		"https://api.example.org":  true,
+		"https://example.org":      false,
+		"https://evil.com":         false,
+		"https://a.b/.example.org": false,
+		"http://localhost:3000":    true,
+		"https://partner.test":     true,
+	}
+
+	for origin, allowed := range cases {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Origin", origin)
+		rec := httptest.NewRecorder()
+		app.ServeHTTP(rec, req)
+
+		allowOrigin := rec.Header().Get("Access-Control-Allow-Origin")
+		if allowed && allowOrigin != origin || !allowed && allowOrigin != "" {
+			t.Errorf("Origin %q expect allowed %v, actual Access-Control-Allow-Origin %q", origin, allowed, allowOrigin)
+		}
+		if rec.Header().Get("Vary") != "Origin" {
+			t.Errorf("Origin %q expect Vary \"Origin\", actual %q", origin, rec.Header().Get("Vary"))
+		}
+		if rec.Body.String() != "OK" {
+			t.Errorf("Origin %q expect handler called, actual body %q", origin, rec.Body.String())
+		}
+	}
+}
+
+func TestCORSPreflight(t *testing.T) {
+	router := dolphin.NewRouter()
+	router.PUT("/users/:id", func(ctx *dolphin.Context) {
+		ctx.String("updated")
+	})
+
+	app := dolphin.New(nil)
+	app.Use(CORS(Config{
+		AllowOrigins:        []string{"https://example.com"},
+		AllowCredentials:    true,
+		AllowPrivateNetwork: true,
+		MaxAge:              10 * time.Minute,
+	}), router.Routes())
+
+	req := httptest.NewRequest(http.MethodOptions, "/users/1", nil)
+	req.Header.Set("Origin", "https://example.com")
+	req.Header.Set("Access-Control-Request-Method", http.MethodPut)
+	req.Header.Set("Access-Control-Request-Headers", "content-type")
+	req.Header.Set("Access-Control-Request-Private-Network", "true")
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("Preflight expect status %d, actual %d", http.StatusNoContent, rec.Code)
+	}
+
+	expected := map[string]string{
+		"Access-Control-Allow-Origin":          "https://example.com",
+		"Access-Control-Allow-Credentials":     "true",
+		"Access-Control-Allow-Methods":         "GET, HEAD, PUT, PATCH, POST, DELETE",
+		"Access-Control-Allow-Headers":         "content-type",
+		"Access-Control-Allow-Private-Network": "true",
+		"Access-Control-Max-Age":               "600",
+	}
+	for key, val := range expected {
+		if actual := rec.Header().Get(key); actual != val {
+			t.Errorf("Preflight header %s expect %q, actual %q", key, val, actual)
+		}
+	}
+}
+
+func TestCORSWildcardCredentials(t *testing.T) {
+	cases := map[string]Config{
+		"Wildcard": {AllowOrigins: []string{"https://example.com", "*"}, AllowCredentials: true},
+		"Default":  {AllowCredentials: true},
+	}
+
+	for name, config := range cases {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if p := recover(); p == nil {
+					t.Error("CORS expect panic, actual nil")
+				}
+			}()
+
+			CORS(config)
+		})
+	}
+}
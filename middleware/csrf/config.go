@@ -0,0 +1,106 @@
+package csrf
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/ghosind/dolphin"
+)
+
+// Pattern is the pattern to store the CSRF tokens.
+type Pattern int
+
+const (
+	// DoubleSubmitCookie stores the token in a signed cookie, and compares it with the token
+	// that submitted in the header or the form. It requires dolphin.Config.CookieKeys.
+	DoubleSubmitCookie Pattern = iota
+	// SynchronizerToken stores the token in the session, it requires the session middleware.
+	SynchronizerToken
+)
+
+// ErrorHandler is the handler that will be triggered when the CSRF verification is failed.
+type ErrorHandler func(ctx *dolphin.Context, err error)
+
+// Config is the CSRF middleware config.
+type Config struct {
+	// ContextKey is the key of the token in the context state, so the token can be read by the
+	// templates through ctx.Keys(). Default "csrf".
+	ContextKey string
+	// CookieDomain is the domain of the token cookie.
+	CookieDomain string
+	// CookieHTTPOnly indicates the token cookie can't be read by the client scripts. The cookie
+	// value is signed, so the scripts should get the token from the page that rendered by Token.
+	CookieHTTPOnly bool
+	// CookieMaxAge is the lifetime of the token cookie, default 12 hours.
+	CookieMaxAge time.Duration
+	// CookieName is the name of the token cookie, default "_csrf".
+	CookieName string
+	// CookiePath is the path of the token cookie, default "/".
+	CookiePath string
+	// CookieSameSite is the SameSite attribute of the token cookie, default
+	// http.SameSiteLaxMode.
+	CookieSameSite http.SameSite
+	// CookieSecure indicates the token cookie is only sent over HTTPS, it's always set for the
+	// requests over TLS.
+	CookieSecure bool
+	// ErrorHandler is the handler for the failed verifications, it'll return a 403 (Forbidden)
+	// response if the handler is not set.
+	ErrorHandler ErrorHandler
+	// ExcludePaths is the list of the paths that are not protected, like the webhooks. A path
+	// ends with "*" matches all paths with the prefix.
+	ExcludePaths []string
+	// Lookup is the list of the places to look up the submitted token in the form of
+	// "<source>:<name>", the source can be "header", "form", or "query". Default
+	// "header:X-CSRF-Token" and "form:_csrf".
+	Lookup []string
+	// Pattern is the pattern to store the tokens, default DoubleSubmitCookie.
+	Pattern Pattern
+	// Skip returns true if the request should not be protected.
+	Skip func(*dolphin.Context) bool
+	// TrustedOrigins is the list of the origins like "https://example.com" that allowed to make
+	// requests besides the same origin, it's used in the Origin and Referer checks.
+	TrustedOrigins []string
+}
+
+// DefaultConfig is the default CSRF middleware config.
+var DefaultConfig = Config{
+	ContextKey:     "csrf",
+	CookieMaxAge:   12 * time.Hour,
+	CookieName:     "_csrf",
+	CookiePath:     "/",
+	CookieSameSite: http.SameSiteLaxMode,
+	ErrorHandler:   defaultErrorHandler,
+	Lookup:         []string{"header:X-CSRF-Token", "form:_csrf"},
+	Pattern:        DoubleSubmitCookie,
+}
+
+func getConfig(config ...Config) Config {
+	if len(config) < 1 {
+		return DefaultConfig
+	}
+
+	cfg := config[0]
+	if cfg.ContextKey == "" {
+		cfg.ContextKey = DefaultConfig.ContextKey
+	}
+	if cfg.CookieMaxAge <= 0 {
+		cfg.CookieMaxAge = DefaultConfig.CookieMaxAge
+	}
+	if cfg.CookieName == "" {
+		cfg.CookieName = DefaultConfig.CookieName
+	}
+	if cfg.CookiePath == "" {
+		cfg.CookiePath = DefaultConfig.CookiePath
+	}
+	if cfg.CookieSameSite == 0 {
+		cfg.CookieSameSite = DefaultConfig.CookieSameSite
+	}
+	if cfg.ErrorHandler == nil {
+		cfg.ErrorHandler = DefaultConfig.ErrorHandler
+	}
+	if len(cfg.Lookup) == 0 {
+		cfg.Lookup = DefaultConfig.Lookup
+	}
+
+	return cfg
+}
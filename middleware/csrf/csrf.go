@@ -0,0 +1,318 @@
+package csrf
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/ghosind/dolphin"
+)
+
+// tokenLength is the length of the raw tokens in bytes.
+const tokenLength = 32
+
+// sessionKey is the key of the token in the session for the synchronizer token pattern.
+const sessionKey = "_csrf_token"
+
+// tokenStateKey is the key of the masked token in the context state.
+const tokenStateKey = "github.com/ghosind/dolphin/middleware/csrf.token"
+
+// fieldNameStateKey is the key of the form field name in the context state.
+const fieldNameStateKey = "github.com/ghosind/dolphin/middleware/csrf.field"
+
+// CSRF returns a middleware that protects the unsafe requests from the cross-site request
+// forgery. The token of the request can be got by Token, and should be submitted in the header
+// or the form field of the unsafe requests.
+//
+// The double-submit cookie pattern signs the token cookie by ctx.SetSignedCookie, so it requires
+// dolphin.Config.CookieKeys, and the synchronizer token pattern requires the session middleware.
+// The missing requirements can only be found in the requests, they're reported by ctx.Error
+// with dolphin.ErrNoCookieKeys or ErrSessionRequired.
+func CSRF(config ...Config) dolphin.HandlerFunc {
+	cfg := getConfig(config...)
+	lookups := parseLookups(cfg.Lookup)
+	fieldName := formFieldName(cfg.Lookup)
+
+	return func(ctx *dolphin.Context) {
+		if cfg.Skip != nil && cfg.Skip(ctx) || isExcluded(ctx.Path(), cfg.ExcludePaths) {
+			ctx.Next()
+			return
+		}
+
+		token, isNew, err := loadToken(ctx, &cfg)
+		if err != nil {
+			ctx.Error(err)
+			return
+		}
+
+		if !isSafeMethod(ctx.Method()) {
+			if err := verify(ctx, &cfg, lookups, token, isNew); err != nil {
+				cfg.ErrorHandler(ctx, err)
+				ctx.Abort()
+				return
+			}
+		}
+
+		if isNew {
+			if err := saveToken(ctx, &cfg, token); err != nil {
+				ctx.Error(err)
+				return
+			}
+		}
+
+		masked := maskToken(token)
+		ctx.Set(tokenStateKey, masked)
+		ctx.Set(fieldNameStateKey, fieldName)
+		ctx.Set(cfg.ContextKey, masked)
+
+		ctx.Next()
+	}
+}
+
+// Token returns the token of the request, it's masked by a random value in each request to
+// mitigate the BREACH attack, so it's different for the same stored token.
+func Token(ctx *dolphin.Context) string {
+	val, _ := ctx.Get(tokenStateKey)
+	token, _ := val.(string)
+
+	return token
+}
+
+// TemplateField returns a hidden input field with the token for the HTML forms.
+//
+//	<form method="post">{{ .csrfField }}</form>
+func TemplateField(ctx *dolphin.Context) template.HTML {
+	val, _ := ctx.Get(fieldNameStateKey)
+	name, _ := val.(string)
+
+	return template.HTML(`<input type="hidden" name="` + template.HTMLEscapeString(name) +
+		`" value="` + template.HTMLEscapeString(Token(ctx)) + `">`)
+}
+
+// verify checks the origin and the submitted token of the unsafe request.
+func verify(
+	ctx *dolphin.Context,
+	cfg *Config,
+	lookups []func(*dolphin.Context) string,
+	token []byte,
+	isNew bool,
+) error {
+	if err := checkOrigin(ctx, cfg); err != nil {
+		return err
+	}
+
+	if isNew {
+		return ErrTokenMissing
+	}
+
+	submitted := ""
+	for _, lookup := range lookups {
+		if submitted = lookup(ctx); submitted != "" {
+			break
+		}
+	}
+	if submitted == "" {
+		return ErrTokenMissing
+	}
+
+	unmasked := unmaskToken(submitted)
+	if unmasked == nil || subtle.ConstantTimeCompare(unmasked, token) != 1 {
+		return ErrTokenInvalid
+	}
+
+	return nil
+}
+
+// checkOrigin checks the Origin header, or the Referer header if the Origin header is not set,
+// is the same origin or a trusted origin. The HTTPS requests must have one of the headers, and
+// the HTTP requests are checked only if one of them is set. The HTTP requests also accept the
+// HTTPS origin of the host, because the TLS may be terminated by a proxy.
+func checkOrigin(ctx *dolphin.Context, cfg *Config) error {
+	origin := ctx.Header("Origin")
+	if origin == "" {
+		referer := ctx.Referer()
+		if referer == "" {
+			if ctx.IsTLS() {
+				return ErrRefererMissing
+			}
+			return nil
+		}
+
+		u, err := url.Parse(referer)
+		if err != nil || u.Host == "" {
+			return ErrOriginMismatch
+		}
+		origin = u.Scheme + "://" + u.Host
+	}
+
+	host := ctx.Request.Host()
+	if strings.EqualFold(origin, "https://"+host) || !ctx.IsTLS() && strings.EqualFold(origin, "http://"+host) {
+		return nil
+	}
+	for _, trusted := range cfg.TrustedOrigins {
+		if strings.EqualFold(origin, trusted) {
+			return nil
+		}
+	}
+
+	return ErrOriginMismatch
+}
+
+// loadToken returns the stored token of the request, or generates a new token if it's not
+// stored. It returns an error if the store of the pattern is not available.
+func loadToken(ctx *dolphin.Context, cfg *Config) ([]byte, bool, error) {
+	var stored string
+
+	if cfg.Pattern == SynchronizerToken {
+		session := ctx.Session()
+		if session == nil {
+			return nil, false, ErrSessionRequired
+		}
+		stored = session.GetString(sessionKey)
+	} else if value, err := ctx.SignedCookie(cfg.CookieName); err == nil {
+		stored = value
+	} else if err == dolphin.ErrNoCookieKeys {
+		return nil, false, err
+	}
+
+	if token, err := base64.RawURLEncoding.DecodeString(stored); err == nil && len(token) == tokenLength {
+		return token, false, nil
+	}
+
+	return randomBytes(tokenLength), true, nil
+}
+
+// saveToken saves the new token to the session or the signed cookie.
+func saveToken(ctx *dolphin.Context, cfg *Config, token []byte) error {
+	encoded := base64.RawURLEncoding.EncodeToString(token)
+
+	if cfg.Pattern == SynchronizerToken {
+		ctx.Session().Set(sessionKey, encoded)
+		return nil
+	}
+
+	return ctx.SetSignedCookie(cfg.CookieName, encoded, dolphin.CookieOptions{
+		DisableHTTPOnly: !cfg.CookieHTTPOnly,
+		Domain:          cfg.CookieDomain,
+		MaxAge:          cfg.CookieMaxAge,
+		Path:            cfg.CookiePath,
+		SameSite:        cfg.CookieSameSite,
+		Secure:          cfg.CookieSecure,
+	})
+}
+
+// maskToken masks the token with a random one-time pad, and returns the encoded pad and the
+// masked token.
+func maskToken(token []byte) string {
+	pad := randomBytes(len(token))
+	masked := make([]byte, len(token)*2)
+	copy(masked, pad)
+	for i := range token {
+		masked[len(token)+i] = pad[i] ^ token[i]
+	}
+
+	return base64.RawURLEncoding.EncodeToString(masked)
+}
+
+// unmaskToken returns the raw token of the masked token, or nil if the masked token is
+// invalid.
+func unmaskToken(masked string) []byte {
+	data, err := base64.RawURLEncoding.DecodeString(masked)
+	if err != nil || len(data) != tokenLength*2 {
+		return nil
+	}
+
+	token := make([]byte, tokenLength)
+	for i := range token {
+		token[i] = data[i] ^ data[tokenLength+i]
+	}
+
+	return token
+}
+
+// randomBytes returns n random bytes.
+func randomBytes(n int) []byte {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		panic(err)
+	}
+
+	return buf
+}
+
+// isSafeMethod reports whether the method is safe that doesn't change the server state.
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+
+	return false
+}
+
+// isExcluded reports whether the path matches one of the excluded paths.
+func isExcluded(path string, excludes []string) bool {
+	for _, exclude := range excludes {
+		if strings.HasSuffix(exclude, "*") {
+			if strings.HasPrefix(path, strings.TrimSuffix(exclude, "*")) {
+				return true
+			}
+		} else if path == exclude {
+			return true
+		}
+	}
+
+	return false
+}
+
+// formFieldName returns the name of the first form lookup, or "_csrf" if there is no form
+// lookup.
+func formFieldName(lookups []string) string {
+	for _, lookup := range lookups {
+		if source, name, ok := strings.Cut(lookup, ":"); ok && strings.EqualFold(source, "form") {
+			return name
+		}
+	}
+
+	return "_csrf"
+}
+
+// parseLookups parses the lookup strings to the functions that get the submitted token from the
+// request. It panics if the source is unknown.
+func parseLookups(lookups []string) []func(*dolphin.Context) string {
+	funcs := make([]func(*dolphin.Context) string, 0, len(lookups))
+
+	for _, lookup := range lookups {
+		source, name, ok := strings.Cut(lookup, ":")
+		if !ok || name == "" {
+			panic("csrf: invalid token lookup " + lookup)
+		}
+
+		switch strings.ToLower(source) {
+		case "header":
+			funcs = append(funcs, func(ctx *dolphin.Context) string {
+				return ctx.Header(name)
+			})
+		case "form":
+			funcs = append(funcs, func(ctx *dolphin.Context) string {
+				return ctx.PostForm(name)
+			})
+		case "query":
+			funcs = append(funcs, func(ctx *dolphin.Context) string {
+				return ctx.Query(name)
+			})
+		default:
+			panic("csrf: unknown token source " + source)
+		}
+	}
+
+	return funcs
+}
+
+func defaultErrorHandler(ctx *dolphin.Context, err error) {
+	ctx.String("Forbidden", http.StatusForbidden)
+}
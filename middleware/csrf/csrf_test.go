@@ -0,0 +1,221 @@
+package csrf
+
+import (
+	"crypto/tls"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/ghosind/dolphin"
+	"github.com/ghosind/dolphin/internal/dolphintest"
+	"github.com/ghosind/dolphin/middleware/session"
+)
+
+// testKeys is the app config with the cookie keys to sign the token cookies.
+var testKeys = &dolphin.Config{CookieKeys: [][]byte{[]byte("secret")}}
+
+// writeToken responds the token of the request.
+func writeToken(ctx *dolphin.Context) {
+	ctx.String(Token(ctx))
+}
+
+func TestDoubleSubmitCookie(t *testing.T) {
+	app := dolphin.New(testKeys)
+	app.Use(CSRF(Config{ExcludePaths: []string{"/webhooks/*"}}), writeToken)
+
+	rec := dolphintest.Serve(app, httptest.NewRequest(http.MethodGet, "/", nil))
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != "_csrf" {
+		t.Fatalf("GET expect token cookie, actual %v", cookies)
+	}
+	token := rec.Body.String()
+	if token == "" {
+		t.Fatalf("GET expect token exposed")
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(cookies[0])
+	rec = dolphintest.Serve(app, req)
+	if rec.Body.String() == token || len(rec.Result().Cookies()) != 0 {
+		t.Errorf("Masked token expect changed without new cookie, actual %q", rec.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set("X-CSRF-Token", token)
+	req.AddCookie(cookies[0])
+	if rec = dolphintest.Serve(app, req); rec.Code != http.StatusOK {
+		t.Errorf("POST with header token expect 200, actual %d", rec.Code)
+	}
+
+	form := url.Values{"_csrf": {token}}
+	req = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.AddCookie(cookies[0])
+	if rec = dolphintest.Serve(app, req); rec.Code != http.StatusOK {
+		t.Errorf("POST with form token expect 200, actual %d", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/", nil)
+	req.AddCookie(cookies[0])
+	if rec = dolphintest.Serve(app, req); rec.Code != http.StatusForbidden {
+		t.Errorf("POST without token expect 403, actual %d", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set("X-CSRF-Token", token)
+	if rec = dolphintest.Serve(app, req); rec.Code != http.StatusForbidden {
+		t.Errorf("POST without cookie expect 403, actual %d", rec.Code)
+	}
+
+	forged := &http.Cookie{Name: "_csrf", Value: base64.RawURLEncoding.EncodeToString(unmaskToken(token))}
+	req = httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set("X-CSRF-Token", token)
+	req.AddCookie(forged)
+	if rec = dolphintest.Serve(app, req); rec.Code != http.StatusForbidden {
+		t.Errorf("POST with unsigned cookie expect 403, actual %d", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set("X-CSRF-Token", maskToken(randomBytes(tokenLength)))
+	req.AddCookie(cookies[0])
+	if rec = dolphintest.Serve(app, req); rec.Code != http.StatusForbidden {
+		t.Errorf("POST with other token expect 403, actual %d", rec.Code)
+	}
+
+	if rec = dolphintest.Serve(app, httptest.NewRequest(http.MethodPost, "/webhooks/github", nil)); rec.Code != http.StatusOK {
+		t.Errorf("Excluded path expect 200, actual %d", rec.Code)
+	}
+}
+
+func TestOriginCheck(t *testing.T) {
+	var reason error
+	app := dolphin.New(testKeys)
+	app.Use(CSRF(Config{
+		ErrorHandler: func(ctx *dolphin.Context, err error) {
+			reason = err
+			ctx.String("Forbidden", http.StatusForbidden)
+		},
+		TrustedOrigins: []string{"https://admin.example.com"},
+	}), writeToken)
+
+	rec := dolphintest.Serve(app, httptest.NewRequest(http.MethodGet, "https://example.com/", nil))
+	cookie := rec.Result().Cookies()[0]
+	token := rec.Body.String()
+
+	cases := []struct {
+		https   bool
+		origin  string
+		referer string
+		err     error
+	}{
+		{true, "https://example.com", "", nil},
+		{true, "https://admin.example.com", "", nil},
+		{true, "", "https://example.com/form", nil},
+		{true, "https://evil.com", "", ErrOriginMismatch},
+		{true, "", "http://example.com/form", ErrOriginMismatch},
+		{true, "", "", ErrRefererMissing},
+		{false, "http://example.com", "", nil},
+		{false, "https://example.com", "", nil},
+		{false, "https://admin.example.com", "", nil},
+		{false, "http://evil.com", "", ErrOriginMismatch},
+		{false, "", "http://evil.com/form", ErrOriginMismatch},
+		{false, "", "", nil},
+	}
+
+	for _, c := range cases {
+		reason = nil
+		req := httptest.NewRequest(http.MethodPost, "http://example.com/", nil)
+		if c.https {
+			req.TLS = &tls.ConnectionState{}
+		}
+		req.Header.Set("X-CSRF-Token", token)
+		if c.origin != "" {
+			req.Header.Set("Origin", c.origin)
+		}
+		if c.referer != "" {
+			req.Header.Set("Referer", c.referer)
+		}
+
+		req.AddCookie(cookie)
+		dolphintest.Serve(app, req)
+		if reason != c.err {
+			t.Errorf("HTTPS %v Origin %q Referer %q expect %v, actual %v", c.https, c.origin, c.referer, c.err, reason)
+		}
+	}
+}
+
+func TestSynchronizerToken(t *testing.T) {
+	app := dolphin.New(testKeys)
+	app.Use(session.Session(), CSRF(Config{Pattern: SynchronizerToken}), writeToken)
+
+	rec := dolphintest.Serve(app, httptest.NewRequest(http.MethodGet, "/", nil))
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != session.DefaultConfig.Name {
+		t.Fatalf("GET expect session cookie only, actual %v", cookies)
+	}
+	token := rec.Body.String()
+
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set("X-CSRF-Token", token)
+	req.AddCookie(cookies[0])
+	if rec = dolphintest.Serve(app, req); rec.Code != http.StatusOK {
+		t.Errorf("POST with token expect 200, actual %d", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set("X-CSRF-Token", token)
+	if rec = dolphintest.Serve(app, req); rec.Code != http.StatusForbidden {
+		t.Errorf("POST without session expect 403, actual %d", rec.Code)
+	}
+}
+
+func TestMissingRequirement(t *testing.T) {
+	cases := []struct {
+		name   string
+		keys   [][]byte
+		config Config
+		err    error
+	}{
+		{"CookieKeys", nil, Config{}, dolphin.ErrNoCookieKeys},
+		{"Session", [][]byte{[]byte("secret")}, Config{Pattern: SynchronizerToken}, ErrSessionRequired},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var reason error
+			app := dolphin.New(&dolphin.Config{
+				CookieKeys: c.keys,
+				ErrorHandler: func(ctx *dolphin.Context, err error) {
+					reason = err
+					dolphin.DefaultErrorHandler(ctx, err)
+				},
+			})
+			app.Use(CSRF(c.config), func(ctx *dolphin.Context) {
+				ctx.String("unexpected")
+			})
+
+			rec := dolphintest.Serve(app, httptest.NewRequest(http.MethodGet, "/", nil))
+			if rec.Code != http.StatusInternalServerError {
+				t.Errorf("Status code expect 500, actual %d", rec.Code)
+			}
+			if reason != c.err {
+				t.Errorf("Error expect %v, actual %v", c.err, reason)
+			}
+		})
+	}
+}
+
+func TestTemplateField(t *testing.T) {
+	app := dolphin.New(testKeys)
+	app.Use(CSRF(), func(ctx *dolphin.Context) {
+		ctx.HTML(string(TemplateField(ctx)))
+	})
+
+	rec := dolphintest.Serve(app, httptest.NewRequest(http.MethodGet, "/", nil))
+	if body := rec.Body.String(); !strings.HasPrefix(body, `<input type="hidden" name="_csrf" value="`) {
+		t.Errorf("TemplateField expect hidden input, actual %q", body)
+	}
+}
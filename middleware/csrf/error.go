@@ -0,0 +1,21 @@
+package csrf
+
+import "errors"
+
+// ErrTokenMissing is returned when the request has no token, or the token cookie is not set.
+var ErrTokenMissing = errors.New("missing CSRF token")
+
+// ErrTokenInvalid is returned when the submitted token doesn't match the stored token.
+var ErrTokenInvalid = errors.New("invalid CSRF token")
+
+// ErrOriginMismatch is returned when the Origin or the Referer header of the request is not the
+// same origin or a trusted origin.
+var ErrOriginMismatch = errors.New("origin not allowed")
+
+// ErrRefererMissing is returned when the HTTPS request has neither the Origin header nor the
+// Referer header.
+var ErrRefererMissing = errors.New("missing referer")
+
+// ErrSessionRequired is reported when the synchronizer token pattern is used without the session
+// middleware.
+var ErrSessionRequired = errors.New("session middleware is required by the synchronizer token pattern")
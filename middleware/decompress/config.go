@@ -0,0 +1,27 @@
+package decompress
+
+// Config is the decompress middleware config.
+type Config struct {
+	// Limit is the maximum size in bytes of the decompressed request body, the request will
+	// be rejected with 413 (Content Too Large) if the decompressed body exceeds the limit.
+	// Default 10 MiB.
+	Limit int64
+}
+
+// DefaultConfig is the default decompress middleware config.
+var DefaultConfig = Config{
+	Limit: 10 << 20,
+}
+
+func getConfig(config ...Config) Config {
+	if len(config) < 1 {
+		return DefaultConfig
+	}
+
+	cfg := config[0]
+	if cfg.Limit <= 0 {
+		cfg.Limit = DefaultConfig.Limit
+	}
+
+	return cfg
+}
@@ -0,0 +1,112 @@
+package decompress
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/ghosind/dolphin"
+)
+
+// Decompress returns a middleware that decodes the request body that encoded by gzip or
+// deflate by the "Content-Encoding" request header, so the following handlers can read the
+// decoded body by Context.Body or Context.PostJSON.
+//
+// It responds 415 (Unsupported Media Type) for unknown encodings, 400 (Bad Request) for
+// corrupted bodies, and 413 (Content Too Large) if the decoded body exceeds the limit.
+func Decompress(config ...Config) dolphin.HandlerFunc {
+	cfg := getConfig(config...)
+
+	return func(ctx *dolphin.Context) {
+		encodings := parseContentEncoding(ctx.MultiValuesHeader("Content-Encoding"))
+		if len(encodings) == 0 {
+			ctx.Next()
+			return
+		}
+
+		body := ctx.Request.BodyReader()
+		defer body.Close()
+
+		var reader io.Reader = body
+		// The encodings are listed in the order in which they were applied, so they should
+		// be decoded in reverse order.
+		for i := len(encodings) - 1; i >= 0; i-- {
+			var err error
+
+			switch encodings[i] {
+			case "gzip", "x-gzip":
+				reader, err = gzip.NewReader(reader)
+			case "deflate":
+				reader, err = newDeflateReader(reader)
+			case "identity":
+				continue
+			default:
+				ctx.SetHeader("Accept-Encoding", "gzip, deflate")
+				ctx.String("Unsupported Media Type", http.StatusUnsupportedMediaType)
+				ctx.Abort()
+				return
+			}
+
+			if err != nil {
+				ctx.String("Bad Request", http.StatusBadRequest)
+				ctx.Abort()
+				return
+			}
+		}
+
+		data, err := io.ReadAll(io.LimitReader(reader, cfg.Limit+1))
+		if err != nil {
+			ctx.String("Bad Request", http.StatusBadRequest)
+			ctx.Abort()
+			return
+		}
+		if int64(len(data)) > cfg.Limit {
+			ctx.String("Request Entity Too Large", http.StatusRequestEntityTooLarge)
+			ctx.Abort()
+			return
+		}
+
+		ctx.Request.SetBody(data)
+		ctx.Request.DelHeader("Content-Encoding")
+
+		ctx.Next()
+	}
+}
+
+// parseContentEncoding returns the lower case content codings in the header values.
+func parseContentEncoding(headers []string) []string {
+	encodings := make([]string, 0, len(headers))
+
+	for _, header := range headers {
+		for _, encoding := range strings.Split(header, ",") {
+			encoding = strings.ToLower(strings.TrimSpace(encoding))
+			if encoding != "" {
+				encodings = append(encodings, encoding)
+			}
+		}
+	}
+
+	return encodings
+}
+
+// newDeflateReader returns the reader of the "deflate" encoded content. The "deflate" coding
+// is the zlib format, but some clients send the raw deflate data, so it'll fall back to the
+// raw deflate format if the zlib header is invalid.
+func newDeflateReader(reader io.Reader) (io.Reader, error) {
+	buffered := bufio.NewReader(reader)
+
+	header, err := buffered.Peek(2)
+	if err != nil {
+		return nil, err
+	}
+
+	if header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return zlib.NewReader(buffered)
+	}
+
+	return flate.NewReader(buffered), nil
+}
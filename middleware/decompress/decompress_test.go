@@ -0,0 +1,71 @@
+package decompress
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ghosind/dolphin"
+)
+
+func TestDecompress(t *testing.T) {
+	payload := `{"name":"dolphin"}`
+
+	gzipBody := new(bytes.Buffer)
+	gw := gzip.NewWriter(gzipBody)
+	gw.Write([]byte(payload))
+	gw.Close()
+
+	zlibBody := new(bytes.Buffer)
+	zw := zlib.NewWriter(zlibBody)
+	zw.Write([]byte(payload))
+	zw.Close()
+
+	bomb := new(bytes.Buffer)
+	bw := gzip.NewWriter(bomb)
+	bw.Write(bytes.Repeat([]byte{'0'}, 2048))
+	bw.Close()
+
+	app := dolphin.New(nil)
+	app.Use(Decompress(Config{Limit: 1024}), func(ctx *dolphin.Context) {
+		var data struct {
+			Name string `json:"name"`
+		}
+		if err := ctx.PostJSON(&data); err != nil {
+			ctx.String("invalid", http.StatusBadRequest)
+			return
+		}
+		ctx.String(data.Name)
+	})
+
+	cases := []struct {
+		encoding string
+		body     []byte
+		code     int
+		expected string
+	}{
+		{"", []byte(payload), http.StatusOK, "dolphin"},
+		{"gzip", gzipBody.Bytes(), http.StatusOK, "dolphin"},
+		{"deflate", zlibBody.Bytes(), http.StatusOK, "dolphin"},
+		{"gzip", []byte("not gzip"), http.StatusBadRequest, "Bad Request"},
+		{"gzip", bomb.Bytes(), http.StatusRequestEntityTooLarge, "Request Entity Too Large"},
+		{"br", []byte(payload), http.StatusUnsupportedMediaType, "Unsupported Media Type"},
+	}
+
+	for _, c := range cases {
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(c.body))
+		if c.encoding != "" {
+			req.Header.Set("Content-Encoding", c.encoding)
+		}
+		rec := httptest.NewRecorder()
+		app.ServeHTTP(rec, req)
+
+		if rec.Code != c.code || strings.TrimSpace(rec.Body.String()) != c.expected {
+			t.Errorf("Encoding %q expect %d %q, actual %d %q", c.encoding, c.code, c.expected, rec.Code, rec.Body.String())
+		}
+	}
+}
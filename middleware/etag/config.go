@@ -0,0 +1,21 @@
+package etag
+
+// Config is the etag middleware config.
+type Config struct {
+	// Strong indicates to generate strong entity tags by the SHA-256 hash of the response
+	// body. It'll generate weak entity tags by the FNV-1a hash if it's not set.
+	Strong bool
+}
+
+// DefaultConfig is the default etag middleware config.
+var DefaultConfig = Config{
+	Strong: false,
+}
+
+func getConfig(config ...Config) Config {
+	if len(config) < 1 {
+		return DefaultConfig
+	}
+
+	return config[0]
+}
@@ -0,0 +1,60 @@
+package etag
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+
+	"github.com/ghosind/dolphin"
+)
+
+// ETag returns a middleware that generates the entity tags for the successful GET and HEAD
+// responses, and responds 304 (Not Modified) or 412 (Precondition Failed) by the conditional
+// request headers. The entity tags that set by the handlers will not be replaced.
+//
+// The preconditions of the unsafe methods like PUT should be checked by the handlers before
+// changing the resources with Context.CheckPreconditions.
+func ETag(config ...Config) dolphin.HandlerFunc {
+	cfg := getConfig(config...)
+
+	return func(ctx *dolphin.Context) {
+		ctx.Next()
+
+		method := ctx.Method()
+		if method != http.MethodGet && method != http.MethodHead {
+			return
+		}
+
+		resp := ctx.Response
+		if resp.StatusCode() != http.StatusOK {
+			return
+		}
+
+		if resp.Header("ETag") == "" {
+			body := resp.Body()
+			if len(body) == 0 || resp.Stream() != nil {
+				// The streaming body can't be hashed without reading it.
+				return
+			}
+
+			resp.SetHeader("ETag", generateETag(body, cfg.Strong))
+		}
+
+		ctx.CheckPreconditions()
+	}
+}
+
+// generateETag generates the entity tag of the response body.
+func generateETag(body []byte, strong bool) string {
+	if strong {
+		sum := sha256.Sum256(body)
+		return `"` + hex.EncodeToString(sum[:16]) + `"`
+	}
+
+	hash := fnv.New64a()
+	hash.Write(body)
+
+	return fmt.Sprintf(`W/"%x-%x"`, len(body), hash.Sum64())
+}
@@ -0,0 +1,110 @@
+package etag
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ghosind/dolphin"
+)
+
+func TestETag(t *testing.T) {
+	weakTag := generateETag([]byte("hello"), false)
+	strongTag := generateETag([]byte("hello"), true)
+
+	cases := []struct {
+		name   string
+		config Config
+		method string
+		header string
+		value  string
+		code   int
+		etag   string
+	}{
+		{"Weak", Config{}, http.MethodGet, "", "", http.StatusOK, weakTag},
+		{"Strong", Config{Strong: true}, http.MethodGet, "", "", http.StatusOK, strongTag},
+		{"Head", Config{}, http.MethodHead, "", "", http.StatusOK, weakTag},
+		{"NoneMatchWeak", Config{}, http.MethodGet, "If-None-Match", weakTag, http.StatusNotModified, weakTag},
+		{"NoneMatchStrongAsWeak", Config{Strong: true}, http.MethodGet, "If-None-Match", "W/" + strongTag, http.StatusNotModified, strongTag},
+		{"NoneMatchList", Config{}, http.MethodGet, "If-None-Match", `"v0", ` + weakTag, http.StatusNotModified, weakTag},
+		{"NoneMatchAny", Config{}, http.MethodGet, "If-None-Match", "*", http.StatusNotModified, weakTag},
+		{"NoneMatchOther", Config{}, http.MethodGet, "If-None-Match", `"v0"`, http.StatusOK, weakTag},
+		{"MatchStrong", Config{Strong: true}, http.MethodGet, "If-Match", strongTag, http.StatusOK, strongTag},
+		{"MatchWeakTag", Config{}, http.MethodGet, "If-Match", weakTag, http.StatusPreconditionFailed, weakTag},
+		{"MatchWeakOfStrong", Config{Strong: true}, http.MethodGet, "If-Match", "W/" + strongTag, http.StatusPreconditionFailed, strongTag},
+		{"MatchOther", Config{Strong: true}, http.MethodGet, "If-Match", `"v0"`, http.StatusPreconditionFailed, strongTag},
+		{"UnsafeMethod", Config{}, http.MethodPost, "If-None-Match", "*", http.StatusOK, ""},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			app := dolphin.New(nil)
+			app.Use(ETag(c.config), func(ctx *dolphin.Context) {
+				ctx.String("hello")
+			})
+
+			req := httptest.NewRequest(c.method, "/", nil)
+			if c.header != "" {
+				req.Header.Set(c.header, c.value)
+			}
+			rec := httptest.NewRecorder()
+			app.ServeHTTP(rec, req)
+
+			if rec.Code != c.code {
+				t.Errorf("Status code expect %d, actual %d", c.code, rec.Code)
+			}
+			if etag := rec.Header().Get("ETag"); etag != c.etag {
+				t.Errorf("ETag expect %q, actual %q", c.etag, etag)
+			}
+			if c.code == http.StatusNotModified && rec.Body.Len() != 0 {
+				t.Errorf("Body expect empty, actual %q", rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestETagSkip(t *testing.T) {
+	cases := []struct {
+		name    string
+		handler dolphin.HandlerFunc
+		code    int
+		etag    string
+	}{
+		{"Stream", func(ctx *dolphin.Context) {
+			ctx.Stream("text/plain", strings.NewReader("hello"))
+		}, http.StatusOK, ""},
+		{"Empty", func(ctx *dolphin.Context) {
+			ctx.SetStatusCode(http.StatusOK)
+		}, http.StatusOK, ""},
+		{"Created", func(ctx *dolphin.Context) {
+			ctx.String("hello", http.StatusCreated)
+		}, http.StatusCreated, ""},
+		{"NotFound", func(ctx *dolphin.Context) {
+			ctx.String("hello", http.StatusNotFound)
+		}, http.StatusNotFound, ""},
+		{"HandlerETag", func(ctx *dolphin.Context) {
+			ctx.SetETag("v1")
+			ctx.String("hello")
+		}, http.StatusNotModified, `"v1"`},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			app := dolphin.New(nil)
+			app.Use(ETag(), c.handler)
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("If-None-Match", "*")
+			rec := httptest.NewRecorder()
+			app.ServeHTTP(rec, req)
+
+			if rec.Code != c.code {
+				t.Errorf("Status code expect %d, actual %d", c.code, rec.Code)
+			}
+			if etag := rec.Header().Get("ETag"); etag != c.etag {
+				t.Errorf("ETag expect %q, actual %q", c.etag, etag)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package jwt
+
+import (
+	"time"
+
+	"github.com/ghosind/dolphin"
+)
+
+// ErrorHandler is the handler that will be triggered when the token verification is failed.
+type ErrorHandler func(ctx *dolphin.Context, err error)
+
+// Config is the JWT middleware config.
+type Config struct {
+	// Algorithms is the list of the allowed signing algorithms, default all supported
+	// algorithms (HS256, HS384, HS512, RS256, and ES256). The algorithm must also match the
+	// type of the key.
+	Algorithms []string
+	// Audience is the expected audience of the tokens, the "aud" claim is not checked if it's
+	// empty.
+	Audience string
+	// ErrorHandler is the handler that will be triggered when the verification is failed, it'll
+	// return a 401 (Unauthorized) response if the handler is not set.
+	ErrorHandler ErrorHandler
+	// Issuer is the expected issuer of the tokens, the "iss" claim is not checked if it's
+	// empty.
+	Issuer string
+	// Key is the key to verify the tokens, it can be a []byte for HMAC, an *rsa.PublicKey for
+	// RS256, or an *ecdsa.PublicKey for ES256. It's used if KeySet is not set.
+	Key any
+	// KeySet is the JSON Web Key Set to verify the tokens, the key is selected by the "kid"
+	// header of the tokens.
+	KeySet *KeySet
+	// Leeway is the allowed clock skew when checking the "exp", "nbf", and "iat" claims.
+	Leeway time.Duration
+	// Lookup is the list of the places to look up the token in the form of "<source>:<name>",
+	// the source can be "header", "query", or "cookie". The header value should have the
+	// "Bearer" scheme. Default "header:Authorization".
+	Lookup []string
+	// NewClaims returns a new claims value to bind the token payload, like `&MyClaims{}`. The
+	// bound claims can be got by GetClaims.
+	NewClaims func() any
+}
+
+// DefaultConfig is the default JWT middleware config.
+var DefaultConfig = Config{
+	Algorithms:   []string{HS256, HS384, HS512, RS256, ES256},
+	ErrorHandler: defaultErrorHandler,
+	Lookup:       []string{"header:Authorization"},
+}
+
+func getConfig(config ...Config) Config {
+	if len(config) < 1 {
+		return DefaultConfig
+	}
+
+	cfg := config[0]
+	if len(cfg.Algorithms) == 0 {
+		cfg.Algorithms = DefaultConfig.Algorithms
+	}
+	if cfg.ErrorHandler == nil {
+		cfg.ErrorHandler = DefaultConfig.ErrorHandler
+	}
+	if len(cfg.Lookup) == 0 {
+		cfg.Lookup = DefaultConfig.Lookup
+	}
+
+	return cfg
+}
@@ -0,0 +1,39 @@
+package jwt
+
+import "errors"
+
+// ErrTokenMissing is returned when no token is found in the request.
+var ErrTokenMissing = errors.New("jwt: token is missing")
+
+// ErrTokenMalformed is returned when the token is not a valid JWS compact serialization.
+var ErrTokenMalformed = errors.New("jwt: token is malformed")
+
+// ErrUnsupportedAlgorithm is returned when the signing algorithm is not allowed or supported.
+var ErrUnsupportedAlgorithm = errors.New("jwt: unsupported signing algorithm")
+
+// ErrKeyNotFound is returned when no key is found to verify the token.
+var ErrKeyNotFound = errors.New("jwt: verification key not found")
+
+// ErrInvalidKey is returned when the key type doesn't match the signing algorithm.
+var ErrInvalidKey = errors.New("jwt: invalid verification key")
+
+// ErrSignatureInvalid is returned when the token signature is invalid.
+var ErrSignatureInvalid = errors.New("jwt: signature is invalid")
+
+// ErrTokenExpired is returned when the token is expired by the "exp" claim.
+var ErrTokenExpired = errors.New("jwt: token is expired")
+
+// ErrTokenNotValidYet is returned when the token is not valid yet by the "nbf" claim.
+var ErrTokenNotValidYet = errors.New("jwt: token is not valid yet")
+
+// ErrTokenUsedBeforeIssued is returned when the "iat" claim is in the future.
+var ErrTokenUsedBeforeIssued = errors.New("jwt: token used before issued")
+
+// ErrInvalidIssuer is returned when the "iss" claim doesn't match the expected issuer.
+var ErrInvalidIssuer = errors.New("jwt: invalid issuer")
+
+// ErrInvalidAudience is returned when the "aud" claim doesn't contain the expected audience.
+var ErrInvalidAudience = errors.New("jwt: invalid audience")
+
+// ErrInvalidKeySet is returned when the JSON Web Key Set document is invalid.
+var ErrInvalidKeySet = errors.New("jwt: invalid key set")
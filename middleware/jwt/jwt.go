@@ -0,0 +1,136 @@
+package jwt
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/ghosind/dolphin"
+)
+
+// tokenKey is the key of the verified token in the context state.
+const tokenKey = "github.com/ghosind/dolphin/middleware/jwt.token"
+
+// claimsKey is the key of the bound claims in the context state.
+const claimsKey = "github.com/ghosind/dolphin/middleware/jwt.claims"
+
+// JWT returns a middleware that verifies the JSON Web Token of the request. The verified token
+// can be got by GetToken, and the claims that bound by Config.NewClaims can be got by
+// GetClaims.
+func JWT(config ...Config) dolphin.HandlerFunc {
+	cfg := getConfig(config...)
+	lookups := parseLookups(cfg.Lookup)
+
+	return func(ctx *dolphin.Context) {
+		raw := ""
+		for _, lookup := range lookups {
+			if raw = lookup(ctx); raw != "" {
+				break
+			}
+		}
+		if raw == "" {
+			cfg.ErrorHandler(ctx, ErrTokenMissing)
+			ctx.Abort()
+			return
+		}
+
+		token, err := Parse(raw, cfg)
+		if err != nil {
+			cfg.ErrorHandler(ctx, err)
+			ctx.Abort()
+			return
+		}
+		ctx.Set(tokenKey, token)
+
+		if cfg.NewClaims != nil {
+			claims := cfg.NewClaims()
+			if err := token.Bind(claims); err != nil {
+				cfg.ErrorHandler(ctx, ErrTokenMalformed)
+				ctx.Abort()
+				return
+			}
+			ctx.Set(claimsKey, claims)
+		}
+
+		ctx.Next()
+	}
+}
+
+// GetToken returns the verified token of the request.
+func GetToken(ctx *dolphin.Context) (*Token, bool) {
+	val, ok := ctx.Get(tokenKey)
+	if !ok {
+		return nil, false
+	}
+
+	token, ok := val.(*Token)
+
+	return token, ok
+}
+
+// GetClaims returns the claims that bound by Config.NewClaims, the type parameter should be
+// the type of the value that returned by NewClaims.
+//
+//	claims, ok := jwt.GetClaims[*MyClaims](ctx)
+func GetClaims[T any](ctx *dolphin.Context) (T, bool) {
+	var claims T
+
+	val, ok := ctx.Get(claimsKey)
+	if !ok {
+		return claims, false
+	}
+
+	claims, ok = val.(T)
+
+	return claims, ok
+}
+
+// parseLookups parses the lookup strings to the functions that get the token from the
+// request. It panics if the source is unknown.
+func parseLookups(lookups []string) []func(*dolphin.Context) string {
+	funcs := make([]func(*dolphin.Context) string, 0, len(lookups))
+
+	for _, lookup := range lookups {
+		source, name, ok := strings.Cut(lookup, ":")
+		if !ok || name == "" {
+			panic("jwt: invalid token lookup " + lookup)
+		}
+
+		switch strings.ToLower(source) {
+		case "header":
+			funcs = append(funcs, func(ctx *dolphin.Context) string {
+				scheme, token, ok := strings.Cut(ctx.Header(name), " ")
+				if !ok || !strings.EqualFold(scheme, "Bearer") {
+					return ""
+				}
+				return strings.TrimSpace(token)
+			})
+		case "query":
+			funcs = append(funcs, func(ctx *dolphin.Context) string {
+				return ctx.Query(name)
+			})
+		case "cookie":
+			funcs = append(funcs, func(ctx *dolphin.Context) string {
+				cookie, err := ctx.Cookie(name)
+				if err != nil {
+					return ""
+				}
+				return cookie.Value
+			})
+		default:
+			panic("jwt: unknown token source " + source)
+		}
+	}
+
+	return funcs
+}
+
+func defaultErrorHandler(ctx *dolphin.Context, err error) {
+	if errors.Is(err, ErrTokenMissing) {
+		ctx.SetHeader("WWW-Authenticate", "Bearer")
+	} else {
+		ctx.SetHeader("WWW-Authenticate", `Bearer error="invalid_token"`)
+	}
+
+	ctx.String("Unauthorized", http.StatusUnauthorized)
+}
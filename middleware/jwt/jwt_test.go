@@ -0,0 +1,239 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/ghosind/dolphin"
+	"github.com/ghosind/dolphin/internal/dolphintest"
+)
+
+// testSign signs the claims with the algorithm and the private key.
+func testSign(t *testing.T, alg, kid string, key any, claims any) string {
+	header, _ := json.Marshal(Header{Algorithm: alg, KeyID: kid, Type: "JWT"})
+	payload, _ := json.Marshal(claims)
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." +
+		base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signingInput))
+
+	var signature []byte
+	switch k := key.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, k)
+		mac.Write([]byte(signingInput))
+		signature = mac.Sum(nil)
+	case *rsa.PrivateKey:
+		var err error
+		signature, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+		if err != nil {
+			t.Fatalf("Failed to sign token: %v", err)
+		}
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, k, digest[:])
+		if err != nil {
+			t.Fatalf("Failed to sign token: %v", err)
+		}
+		signature = make([]byte, 64)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// getToken returns the verified token of the request, or nil if it is not verified.
+func getToken(ctx *dolphin.Context) *Token {
+	token, _ := GetToken(ctx)
+	return token
+}
+
+func TestJWTHMAC(t *testing.T) {
+	secret := []byte("secret")
+	middleware := JWT(Config{Key: secret})
+
+	raw := testSign(t, HS256, "", secret, map[string]any{"sub": "dolphin"})
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer "+raw)
+	rec, token := dolphintest.Capture(req, middleware, getToken)
+	if rec.Code != http.StatusOK || token == nil || token.Claims.Subject != "dolphin" {
+		t.Errorf("Valid token expect authenticated, actual %d %+v", rec.Code, token)
+	}
+
+	rec, _ = dolphintest.Capture(httptest.NewRequest(http.MethodGet, "/", nil), middleware, getToken)
+	if rec.Code != http.StatusUnauthorized || rec.Header().Get("WWW-Authenticate") != "Bearer" {
+		t.Errorf("Missing token expect 401, actual %d %q", rec.Code, rec.Header().Get("WWW-Authenticate"))
+	}
+
+	raw = testSign(t, HS256, "", []byte("other"), map[string]any{"sub": "dolphin"})
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer "+raw)
+	rec, _ = dolphintest.Capture(req, middleware, getToken)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Invalid signature expect 401, actual %d", rec.Code)
+	}
+	if challenge := rec.Header().Get("WWW-Authenticate"); challenge != `Bearer error="invalid_token"` {
+		t.Errorf("Invalid signature expect invalid_token challenge, actual %q", challenge)
+	}
+}
+
+func TestJWTAlgorithmConfusion(t *testing.T) {
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+
+	raw := testSign(t, HS256, "", []byte("secret"), map[string]any{})
+	if _, err := Parse(raw, Config{Key: &key.PublicKey}); err != ErrInvalidKey {
+		t.Errorf("HS256 token with RSA key expect ErrInvalidKey, actual %v", err)
+	}
+
+	raw = testSign(t, RS256, "", key, map[string]any{})
+	if _, err := Parse(raw, Config{Key: &key.PublicKey, Algorithms: []string{ES256}}); err != ErrUnsupportedAlgorithm {
+		t.Errorf("Disallowed algorithm expect ErrUnsupportedAlgorithm, actual %v", err)
+	}
+
+	raw = "eyJhbGciOiJub25lIn0.e30."
+	if _, err := Parse(raw, Config{Key: []byte("secret")}); err != ErrUnsupportedAlgorithm {
+		t.Errorf("None algorithm expect ErrUnsupportedAlgorithm, actual %v", err)
+	}
+}
+
+func TestJWTKeySet(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	encode := func(b []byte) string {
+		return base64.RawURLEncoding.EncodeToString(b)
+	}
+
+	jwks := func(keys ...map[string]string) []byte {
+		data, _ := json.Marshal(map[string]any{"keys": keys})
+		return data
+	}
+	rsaJWK := map[string]string{
+		"kty": "RSA",
+		"kid": "rsa-1",
+		"n":   encode(rsaKey.N.Bytes()),
+		"e":   encode(big.NewInt(int64(rsaKey.E)).Bytes()),
+	}
+	ecJWK := map[string]string{
+		"kty": "EC",
+		"kid": "ec-1",
+		"crv": "P-256",
+		"x":   encode(ecKey.X.FillBytes(make([]byte, 32))),
+		"y":   encode(ecKey.Y.FillBytes(make([]byte, 32))),
+	}
+
+	fsys := fstest.MapFS{"jwks.json": {Data: jwks(rsaJWK)}}
+	keySet, err := LoadKeySetFS(fsys, "jwks.json")
+	if err != nil {
+		t.Fatalf("LoadKeySetFS expect no error, actual %v", err)
+	}
+	cfg := Config{KeySet: keySet}
+
+	if _, err := Parse(testSign(t, RS256, "rsa-1", rsaKey, map[string]any{}), cfg); err != nil {
+		t.Errorf("RS256 token expect verified, actual %v", err)
+	}
+	if _, err := Parse(testSign(t, ES256, "ec-1", ecKey, map[string]any{}), cfg); err != ErrKeyNotFound {
+		t.Errorf("Unknown key ID expect ErrKeyNotFound, actual %v", err)
+	}
+
+	// Rotation
+	fsys["jwks.json"] = &fstest.MapFile{Data: jwks(rsaJWK, ecJWK)}
+	if err := keySet.Reload(); err != nil {
+		t.Fatalf("Reload expect no error, actual %v", err)
+	}
+	if _, err := Parse(testSign(t, ES256, "ec-1", ecKey, map[string]any{}), cfg); err != nil {
+		t.Errorf("ES256 token after rotation expect verified, actual %v", err)
+	}
+
+	if _, err := ParseKeySet([]byte(`{"keys":[{"kty":"EC","crv":"P-256","x":"AA","y":"AA"}]}`)); err != ErrInvalidKeySet {
+		t.Errorf("Invalid EC point expect ErrInvalidKeySet, actual %v", err)
+	}
+}
+
+func TestJWTValidateClaims(t *testing.T) {
+	secret := []byte("secret")
+	now := time.Now()
+
+	cases := []struct {
+		claims map[string]any
+		err    error
+	}{
+		{map[string]any{"exp": now.Add(time.Minute).Unix()}, nil},
+		{map[string]any{"exp": now.Add(-time.Minute).Unix()}, ErrTokenExpired},
+		{map[string]any{"exp": now.Add(-10 * time.Second).Unix()}, nil},
+		{map[string]any{"nbf": now.Add(time.Minute).Unix()}, ErrTokenNotValidYet},
+		{map[string]any{"iat": now.Add(time.Minute).Unix()}, ErrTokenUsedBeforeIssued},
+		{map[string]any{"iss": "other"}, ErrInvalidIssuer},
+		{map[string]any{"aud": []string{"web", "api"}}, nil},
+		{map[string]any{"aud": "web"}, ErrInvalidAudience},
+	}
+
+	for _, c := range cases {
+		c.claims["sub"] = "dolphin"
+		if _, ok := c.claims["iss"]; !ok {
+			c.claims["iss"] = "dolphin"
+		}
+		if _, ok := c.claims["aud"]; !ok {
+			c.claims["aud"] = "api"
+		}
+
+		raw := testSign(t, HS256, "", secret, c.claims)
+		_, err := Parse(raw, Config{
+			Audience: "api",
+			Issuer:   "dolphin",
+			Key:      secret,
+			Leeway:   30 * time.Second,
+		})
+		if err != c.err {
+			t.Errorf("Claims %v expect %v, actual %v", c.claims, c.err, err)
+		}
+	}
+}
+
+func TestJWTLookupAndClaims(t *testing.T) {
+	type userClaims struct {
+		RegisteredClaims
+		Role string `json:"role"`
+	}
+
+	secret := []byte("secret")
+	var claims *userClaims
+
+	app := dolphin.New(nil)
+	app.Use(JWT(Config{
+		Key:       secret,
+		Lookup:    []string{"header:Authorization", "cookie:token", "query:token"},
+		NewClaims: func() any { return &userClaims{} },
+	}), func(ctx *dolphin.Context) {
+		claims, _ = GetClaims[*userClaims](ctx)
+		ctx.String("OK")
+	})
+
+	raw := testSign(t, HS256, "", secret, map[string]any{"sub": "dolphin", "role": "admin"})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(&http.Cookie{Name: "token", Value: raw})
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || claims == nil || claims.Role != "admin" || claims.Subject != "dolphin" {
+		t.Errorf("Cookie token expect claims bound, actual %d %+v", rec.Code, claims)
+	}
+
+	claims = nil
+	rec = httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?token="+raw, nil))
+	if rec.Code != http.StatusOK || claims == nil || claims.Role != "admin" {
+		t.Errorf("Query token expect claims bound, actual %d %+v", rec.Code, claims)
+	}
+}
@@ -0,0 +1,248 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"io/fs"
+	"math/big"
+	"os"
+	"sync"
+	"time"
+)
+
+// reloadInterval is the minimum interval of reloading the key set for the unknown key IDs.
+const reloadInterval = time.Minute
+
+// jsonWebKey is a parsed JSON Web Key.
+type jsonWebKey struct {
+	alg string
+	key any
+	kid string
+	kty string
+}
+
+// KeySet is a JSON Web Key Set (RFC 7517) to verify the tokens. The keys are selected by the
+// "kid" header of the tokens, so the keys can be rotated by adding the new keys to the set
+// before signing tokens with them.
+//
+// The key set that loaded from a file is reloaded when a token with an unknown key ID is
+// received, at most once per minute. It can also be reloaded manually by Reload.
+type KeySet struct {
+	keys       []*jsonWebKey
+	lastReload time.Time
+	load       func() ([]byte, error)
+	mu         sync.RWMutex
+}
+
+// ParseKeySet parses the JSON Web Key Set document.
+func ParseKeySet(data []byte) (*KeySet, error) {
+	keys, err := parseKeySet(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &KeySet{keys: keys, lastReload: time.Now()}, nil
+}
+
+// LoadKeySetFile loads the JSON Web Key Set document from the file.
+func LoadKeySetFile(path string) (*KeySet, error) {
+	return loadKeySet(func() ([]byte, error) {
+		return os.ReadFile(path)
+	})
+}
+
+// LoadKeySetFS loads the JSON Web Key Set document from the file in the file system.
+func LoadKeySetFS(fsys fs.FS, name string) (*KeySet, error) {
+	return loadKeySet(func() ([]byte, error) {
+		return fs.ReadFile(fsys, name)
+	})
+}
+
+// loadKeySet creates a key set with the loader, and loads the keys.
+func loadKeySet(load func() ([]byte, error)) (*KeySet, error) {
+	ks := &KeySet{load: load}
+	if err := ks.Reload(); err != nil {
+		return nil, err
+	}
+
+	return ks, nil
+}
+
+// Reload reloads the keys from the source of the key set, it does nothing if the key set is
+// created by ParseKeySet.
+func (ks *KeySet) Reload() error {
+	if ks.load == nil {
+		return nil
+	}
+
+	data, err := ks.load()
+	if err != nil {
+		return err
+	}
+
+	keys, err := parseKeySet(data)
+	if err != nil {
+		return err
+	}
+
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	ks.keys = keys
+	ks.lastReload = time.Now()
+
+	return nil
+}
+
+// lookup returns the key with the key ID and the algorithm, it tries to reload the key set if
+// the key is not found.
+func (ks *KeySet) lookup(kid, alg string) (any, error) {
+	if key := ks.find(kid, alg); key != nil {
+		return key, nil
+	}
+
+	ks.mu.RLock()
+	canReload := ks.load != nil && time.Since(ks.lastReload) >= reloadInterval
+	ks.mu.RUnlock()
+
+	if canReload {
+		if err := ks.Reload(); err != nil {
+			return nil, err
+		}
+
+		if key := ks.find(kid, alg); key != nil {
+			return key, nil
+		}
+	}
+
+	return nil, ErrKeyNotFound
+}
+
+// find returns the key with the key ID that compatible with the algorithm. If the key ID is
+// empty, it returns the only compatible key in the set.
+func (ks *KeySet) find(kid, alg string) any {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	var found any
+	count := 0
+
+	for _, key := range ks.keys {
+		if key.alg != "" && key.alg != alg || keyTypeOf(alg) != key.kty {
+			continue
+		}
+
+		if kid != "" {
+			if key.kid == kid {
+				return key.key
+			}
+			continue
+		}
+
+		found = key.key
+		count++
+	}
+
+	if count == 1 {
+		return found
+	}
+
+	return nil
+}
+
+// parseKeySet parses the JSON Web Key Set document, the keys with unsupported types or not
+// for signatures are ignored.
+func parseKeySet(data []byte) ([]*jsonWebKey, error) {
+	var doc struct {
+		Keys []struct {
+			Alg string `json:"alg"`
+			Crv string `json:"crv"`
+			E   string `json:"e"`
+			K   string `json:"k"`
+			Kid string `json:"kid"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+			Use string `json:"use"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		} `json:"keys"`
+	}
+
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, ErrInvalidKeySet
+	}
+
+	keys := make([]*jsonWebKey, 0, len(doc.Keys))
+	for _, k := range doc.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		key := &jsonWebKey{alg: k.Alg, kid: k.Kid, kty: k.Kty}
+
+		switch k.Kty {
+		case "RSA":
+			n, errN := base64.RawURLEncoding.DecodeString(k.N)
+			e, errE := base64.RawURLEncoding.DecodeString(k.E)
+			if errN != nil || errE != nil || len(n) == 0 || len(e) == 0 || len(e) > 4 {
+				return nil, ErrInvalidKeySet
+			}
+
+			key.key = &rsa.PublicKey{
+				N: new(big.Int).SetBytes(n),
+				E: int(new(big.Int).SetBytes(e).Int64()),
+			}
+		case "EC":
+			if k.Crv != "P-256" {
+				continue
+			}
+
+			x, errX := base64.RawURLEncoding.DecodeString(k.X)
+			y, errY := base64.RawURLEncoding.DecodeString(k.Y)
+			if errX != nil || errY != nil {
+				return nil, ErrInvalidKeySet
+			}
+
+			publicKey := &ecdsa.PublicKey{
+				Curve: elliptic.P256(),
+				X:     new(big.Int).SetBytes(x),
+				Y:     new(big.Int).SetBytes(y),
+			}
+			if !publicKey.Curve.IsOnCurve(publicKey.X, publicKey.Y) {
+				return nil, ErrInvalidKeySet
+			}
+
+			key.key = publicKey
+		case "oct":
+			secret, err := base64.RawURLEncoding.DecodeString(k.K)
+			if err != nil || len(secret) == 0 {
+				return nil, ErrInvalidKeySet
+			}
+
+			key.key = secret
+		default:
+			continue
+		}
+
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// keyTypeOf returns the JSON Web Key type of the algorithm.
+func keyTypeOf(alg string) string {
+	switch alg {
+	case HS256, HS384, HS512:
+		return "oct"
+	case RS256:
+		return "RSA"
+	case ES256:
+		return "EC"
+	}
+
+	return ""
+}
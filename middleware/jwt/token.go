@@ -0,0 +1,273 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"math"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// The supported signing algorithms.
+const (
+	HS256 = "HS256"
+	HS384 = "HS384"
+	HS512 = "HS512"
+	RS256 = "RS256"
+	ES256 = "ES256"
+)
+
+// Header is the JOSE header of the token.
+type Header struct {
+	// Algorithm is the signing algorithm.
+	Algorithm string `json:"alg"`
+	// KeyID is the identifier of the signing key.
+	KeyID string `json:"kid,omitempty"`
+	// Type is the media type of the token.
+	Type string `json:"typ,omitempty"`
+}
+
+// NumericDate is the JSON numeric date value that represents the seconds since the epoch.
+type NumericDate struct {
+	time.Time
+}
+
+// Audience is the "aud" claim, it can be a string or an array of strings in JSON.
+type Audience []string
+
+// RegisteredClaims is the registered claims of the token.
+type RegisteredClaims struct {
+	Audience  Audience     `json:"aud,omitempty"`
+	ExpiresAt *NumericDate `json:"exp,omitempty"`
+	ID        string       `json:"jti,omitempty"`
+	IssuedAt  *NumericDate `json:"iat,omitempty"`
+	Issuer    string       `json:"iss,omitempty"`
+	NotBefore *NumericDate `json:"nbf,omitempty"`
+	Subject   string       `json:"sub,omitempty"`
+}
+
+// Token is the verified JSON Web Token.
+type Token struct {
+	// Claims is the registered claims of the token.
+	Claims RegisteredClaims
+	// Header is the JOSE header of the token.
+	Header Header
+	// Raw is the raw token string.
+	Raw string
+	// payload is the decoded payload of the token.
+	payload []byte
+}
+
+// Bind unmarshals the token payload into the given value.
+func (token *Token) Bind(v any) error {
+	return json.Unmarshal(token.payload, v)
+}
+
+// Parse verifies the token string with the config, and returns the verified token.
+func Parse(raw string, config ...Config) (*Token, error) {
+	cfg := getConfig(config...)
+
+	return parse(raw, &cfg, time.Now())
+}
+
+// parse verifies the token string with the config and the current time.
+func parse(raw string, cfg *Config, now time.Time) (*Token, error) {
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return nil, ErrTokenMalformed
+	}
+
+	token := &Token{Raw: raw}
+
+	headerData, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, ErrTokenMalformed
+	}
+	if err := json.Unmarshal(headerData, &token.Header); err != nil {
+		return nil, ErrTokenMalformed
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrTokenMalformed
+	}
+
+	if !isAllowedAlgorithm(token.Header.Algorithm, cfg.Algorithms) {
+		return nil, ErrUnsupportedAlgorithm
+	}
+
+	key, err := selectKey(&token.Header, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	signingInput := raw[:len(parts[0])+1+len(parts[1])]
+	if err := verifySignature(token.Header.Algorithm, key, signingInput, signature); err != nil {
+		return nil, err
+	}
+
+	token.payload, err = base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrTokenMalformed
+	}
+	if err := json.Unmarshal(token.payload, &token.Claims); err != nil {
+		return nil, ErrTokenMalformed
+	}
+
+	if err := validateClaims(&token.Claims, cfg, now); err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// selectKey returns the key to verify the token by the header.
+func selectKey(header *Header, cfg *Config) (any, error) {
+	if cfg.KeySet != nil {
+		return cfg.KeySet.lookup(header.KeyID, header.Algorithm)
+	}
+
+	if cfg.Key == nil {
+		return nil, ErrKeyNotFound
+	}
+
+	return cfg.Key, nil
+}
+
+// verifySignature verifies the signature of the signing input with the algorithm and the key.
+func verifySignature(alg string, key any, signingInput string, signature []byte) error {
+	switch alg {
+	case HS256, HS384, HS512:
+		secret, ok := key.([]byte)
+		if !ok || len(secret) == 0 {
+			return ErrInvalidKey
+		}
+
+		hash := sha256.New
+		if alg == HS384 {
+			hash = sha512.New384
+		} else if alg == HS512 {
+			hash = sha512.New
+		}
+
+		mac := hmac.New(hash, secret)
+		mac.Write([]byte(signingInput))
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return ErrSignatureInvalid
+		}
+	case RS256:
+		publicKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return ErrInvalidKey
+		}
+
+		digest := sha256.Sum256([]byte(signingInput))
+		if err := rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, digest[:], signature); err != nil {
+			return ErrSignatureInvalid
+		}
+	case ES256:
+		publicKey, ok := key.(*ecdsa.PublicKey)
+		if !ok || publicKey.Curve.Params().BitSize != 256 {
+			return ErrInvalidKey
+		}
+		if len(signature) != 64 {
+			return ErrSignatureInvalid
+		}
+
+		digest := sha256.Sum256([]byte(signingInput))
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		if !ecdsa.Verify(publicKey, digest[:], r, s) {
+			return ErrSignatureInvalid
+		}
+	default:
+		return ErrUnsupportedAlgorithm
+	}
+
+	return nil
+}
+
+// validateClaims validates the time-based claims, the issuer, and the audience.
+func validateClaims(claims *RegisteredClaims, cfg *Config, now time.Time) error {
+	if claims.ExpiresAt != nil && !now.Before(claims.ExpiresAt.Add(cfg.Leeway)) {
+		return ErrTokenExpired
+	}
+	if claims.NotBefore != nil && now.Add(cfg.Leeway).Before(claims.NotBefore.Time) {
+		return ErrTokenNotValidYet
+	}
+	if claims.IssuedAt != nil && now.Add(cfg.Leeway).Before(claims.IssuedAt.Time) {
+		return ErrTokenUsedBeforeIssued
+	}
+
+	if cfg.Issuer != "" && claims.Issuer != cfg.Issuer {
+		return ErrInvalidIssuer
+	}
+
+	if cfg.Audience != "" {
+		found := false
+		for _, aud := range claims.Audience {
+			if aud == cfg.Audience {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return ErrInvalidAudience
+		}
+	}
+
+	return nil
+}
+
+// isAllowedAlgorithm reports whether the algorithm is in the allowed list.
+func isAllowedAlgorithm(alg string, allowed []string) bool {
+	for _, a := range allowed {
+		if a == alg {
+			return true
+		}
+	}
+
+	return false
+}
+
+// UnmarshalJSON unmarshals the numeric date from a JSON number.
+func (date *NumericDate) UnmarshalJSON(data []byte) error {
+	var seconds float64
+	if err := json.Unmarshal(data, &seconds); err != nil {
+		return err
+	}
+
+	sec, frac := math.Modf(seconds)
+	date.Time = time.Unix(int64(sec), int64(frac*1e9))
+
+	return nil
+}
+
+// MarshalJSON marshals the numeric date to a JSON number.
+func (date NumericDate) MarshalJSON() ([]byte, error) {
+	return json.Marshal(date.Unix())
+}
+
+// UnmarshalJSON unmarshals the audience from a JSON string or an array of strings.
+func (aud *Audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*aud = Audience{single}
+		return nil
+	}
+
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return err
+	}
+	*aud = multiple
+
+	return nil
+}
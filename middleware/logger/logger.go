@@ -1,63 +1,283 @@
 package logger
 
 import (
+	"bytes"
+	"context"
 	"io"
+	"log/slog"
+	"math/rand"
+	"strconv"
+	"sync"
 	"text/template"
 	"time"
 
 	"github.com/ghosind/dolphin"
 )
 
+// Style is the output style of the logger middleware.
+type Style int
+
+const (
+	// StyleTemplate renders the logs by the Format template.
+	StyleTemplate Style = iota
+	// StyleJSON writes the logs as the structured JSON records.
+	StyleJSON
+	// StyleLogfmt writes the logs as the structured logfmt (key=value) records.
+	StyleLogfmt
+	// StyleSlog writes the logs to the app structured logger by ctx.Logger(), the records have
+	// the request attributes of the context logger.
+	StyleSlog
+)
+
+// Preset is the predefined log format.
+type Preset int
+
+const (
+	// PresetDefault is the default format like "GET /path 200 1ms".
+	PresetDefault Preset = iota
+	// PresetCommon is the Common Log Format of the web servers.
+	PresetCommon
+	// PresetCombined is the Combined Log Format, it's the Common Log Format with the referer and
+	// the user agent.
+	PresetCombined
+	// PresetJSON writes the logs as the structured JSON records, it's the same as StyleJSON.
+	PresetJSON
+)
+
+// The formats of the presets.
+const (
+	FormatDefault  = "{{.Method}} {{.Path}} {{.StatusCode}} {{.MilLatency}}ms\n"
+	FormatCommon   = `{{.IP}} - {{.User}} [{{.Time.Format "02/Jan/2006:15:04:05 -0700"}}] "{{.Method}} {{.Path}} {{.Proto}}" {{.StatusCode}} {{.Size}}` + "\n"
+	FormatCombined = `{{.IP}} - {{.User}} [{{.Time.Format "02/Jan/2006:15:04:05 -0700"}}] "{{.Method}} {{.Path}} {{.Proto}}" {{.StatusCode}} {{.Size}} "{{.Referer}}" "{{.UserAgent}}"` + "\n"
+)
+
+// defaultFlushInterval is the default interval to flush the buffered logs.
+const defaultFlushInterval = time.Second
+
 // Congfig is the config for Logger middleware.
 type Congfig struct {
-	// Format is the log format.
+	// App is the app to register the shutdown hook that flushes the buffered logs, it's required
+	// if BufferSize is set.
+	App *dolphin.App
+	// BufferSize is the size of the output buffer, the logs are written to the output directly
+	// if it's zero. The buffered logs are flushed when the buffer is full or after the
+	// FlushInterval.
+	BufferSize int
+	// FlushInterval is the maximum duration to keep the logs in the buffer, default 1 second.
+	FlushInterval time.Duration
+	// Format is the log format, it overrides the format of the preset. A newline is appended
+	// if the format doesn't end with it.
 	Format *string
 	Output *io.Writer
+	// Preset is the predefined format, default PresetDefault.
+	Preset Preset
+	// SampleRate is the ratio of the requests to log between 0 and 1, the failed requests
+	// (status code 5xx or with errors) are always logged. All requests are logged if it's zero.
+	SampleRate float64
+	// Skip returns true if the request should not be logged, it's called after the request is
+	// handled.
+	Skip func(*dolphin.Context) bool
+	// Style is the output style, default StyleTemplate. The Format is ignored for the
+	// structured styles.
+	Style Style
 }
 
 type requestLogData struct {
+	// Errors is the errors that recorded by ctx.AddError.
+	Errors []error
 	// IP is the request client ip.
 	IP string
+	// Latency is the latency of the request.
+	Latency time.Duration
 	// MilLatency is the latency in milliseconds.
 	MilLatency int64
 	// Method is the request method.
 	Method string
 	// Path is the request path with the query string.
 	Path string
+	// Proto is the protocol of the request, like "HTTP/1.1".
+	Proto string
+	// Referer is the request "Referer" header.
+	Referer string
+	// RequestID is the request identifier that set by the request ID middleware.
+	RequestID string
+	// RequestSize is the size of the request body, it's -1 if the size is unknown.
+	RequestSize int64
+	// ResponseSize is the size of the response body, it's -1 if the size is unknown.
+	ResponseSize int64
+	// Route is the pattern of the matched route.
+	Route string
+	// Size is the response size for the Common Log Format, it's "-" if the size is zero or
+	// unknown.
+	Size string
 	// StatusCode is the response status code.
 	StatusCode int
+	// Time is the time that the request is received.
+	Time time.Time
+	// User is the username of the Basic authentication, or "-" if it's not provided.
+	User string
+	// UserAgent is the request "User-Agent" header.
+	UserAgent string
 }
 
 // Logger is the builtin logger middleware for log requests' information.
 func Logger(config ...Congfig) dolphin.HandlerFunc {
 	cfg := getConfig(config...)
 	tpl := template.Must(template.New("LoggerFormat").Parse(*cfg.Format))
+	buffers := &bufferedWriters{size: cfg.BufferSize, interval: cfg.FlushInterval}
+	if cfg.BufferSize > 0 {
+		cfg.App.OnShutdown(func(ctx context.Context) error {
+			buffers.flush()
+			return nil
+		})
+	}
 
 	return func(ctx *dolphin.Context) {
 		start := time.Now()
-		data := requestLogData{
-			IP:     ctx.IP(),
-			Method: ctx.Method(),
-			Path:   ctx.Path(),
+
+		ctx.Next()
+
+		if cfg.Skip != nil && cfg.Skip(ctx) {
+			return
 		}
 
-		if query := ctx.RawQuery(); query != "" {
-			data.Path += "?" + query
+		errs := ctx.Errors()
+		statusCode := ctx.Response.StatusCode()
+		if cfg.SampleRate > 0 && cfg.SampleRate < 1 && statusCode < 500 && len(errs) == 0 &&
+			rand.Float64() >= cfg.SampleRate {
+			return
 		}
 
-		ctx.Next()
+		data := newRequestLogData(ctx, start, errs)
 
-		latency := time.Since(start)
-		data.MilLatency = latency.Milliseconds()
-		data.StatusCode = ctx.Response.StatusCode()
+		var output io.Writer
+		if cfg.Output != nil {
+			output = *cfg.Output
+		} else {
+			output = ctx.LoggerWriter()
+		}
+		if cfg.BufferSize > 0 {
+			output = buffers.get(output)
+		}
 
-		output := cfg.Output
-		if output == nil {
-			appWriter := ctx.LoggerWriter()
-			output = &appWriter
+		buf := new(bytes.Buffer)
+		switch cfg.Style {
+		case StyleJSON:
+			logRecord(ctx, slog.New(slog.NewJSONHandler(buf, nil)), data, true)
+		case StyleLogfmt:
+			logRecord(ctx, slog.New(slog.NewTextHandler(buf, nil)), data, true)
+		case StyleSlog:
+			logRecord(ctx, ctx.Logger(), data, false)
+			return
+		default:
+			tpl.Execute(buf, data)
+			if buf.Len() > 0 && buf.Bytes()[buf.Len()-1] != '\n' {
+				buf.WriteByte('\n')
+			}
 		}
-		tpl.Execute(*output, data)
+
+		output.Write(buf.Bytes())
+	}
+}
+
+// SkipPaths returns a skip function that skips the requests with the paths, like the health
+// checks.
+func SkipPaths(paths ...string) func(*dolphin.Context) bool {
+	skipped := make(map[string]struct{}, len(paths))
+	for _, path := range paths {
+		skipped[path] = struct{}{}
+	}
+
+	return func(ctx *dolphin.Context) bool {
+		_, ok := skipped[ctx.Path()]
+		return ok
+	}
+}
+
+// newRequestLogData collects the log data of the handled request.
+func newRequestLogData(ctx *dolphin.Context, start time.Time, errs []error) *requestLogData {
+	latency := time.Since(start)
+
+	data := &requestLogData{
+		Errors:       errs,
+		IP:           ctx.IP(),
+		Latency:      latency,
+		MilLatency:   latency.Milliseconds(),
+		Method:       ctx.Method(),
+		Path:         ctx.Path(),
+		Proto:        ctx.Request.Proto(),
+		Referer:      ctx.Referer(),
+		RequestID:    ctx.RequestID(),
+		RequestSize:  ctx.Request.ContentLength(),
+		ResponseSize: ctx.Response.Size(),
+		Route:        ctx.RoutePattern(),
+		Size:         "-",
+		StatusCode:   ctx.Response.StatusCode(),
+		Time:         start,
+		User:         "-",
+		UserAgent:    ctx.UserAgent(),
 	}
+
+	if query := ctx.RawQuery(); query != "" {
+		data.Path += "?" + query
+	}
+	if data.StatusCode == 0 {
+		data.StatusCode = 200
+	}
+	if data.ResponseSize > 0 {
+		data.Size = strconv.FormatInt(data.ResponseSize, 10)
+	}
+	if username, _, ok := ctx.BasicAuth(); ok && username != "" {
+		data.User = username
+	}
+
+	return data
+}
+
+// logRecord writes the structured record of the request to the logger, the request attributes
+// are omitted if the logger is the context logger that already has them.
+func logRecord(ctx *dolphin.Context, logger *slog.Logger, data *requestLogData, withRequestAttrs bool) {
+	attrs := make([]slog.Attr, 0, 16)
+	if withRequestAttrs {
+		if data.RequestID != "" {
+			attrs = append(attrs, slog.String("request_id", data.RequestID))
+		}
+		attrs = append(attrs, slog.String("method", data.Method))
+		if data.Route != "" {
+			attrs = append(attrs, slog.String("route", data.Route))
+		}
+		attrs = append(attrs, slog.String("ip", data.IP))
+	}
+
+	attrs = append(attrs,
+		slog.String("path", data.Path),
+		slog.String("proto", data.Proto),
+		slog.Int("status", data.StatusCode),
+		slog.Int64("latency_us", data.Latency.Microseconds()),
+		slog.Int64("bytes_in", data.RequestSize),
+		slog.Int64("bytes_out", data.ResponseSize),
+		slog.String("user_agent", data.UserAgent),
+	)
+	if data.Referer != "" {
+		attrs = append(attrs, slog.String("referer", data.Referer))
+	}
+
+	if len(data.Errors) > 0 {
+		messages := make([]string, 0, len(data.Errors))
+		for _, err := range data.Errors {
+			messages = append(messages, err.Error())
+		}
+		attrs = append(attrs, slog.Any("errors", messages))
+	}
+
+	level := slog.LevelInfo
+	if data.StatusCode >= 500 {
+		level = slog.LevelError
+	} else if data.StatusCode >= 400 {
+		level = slog.LevelWarn
+	}
+
+	logger.LogAttrs(ctx, level, "request", attrs...)
 }
 
 func getConfig(cfg ...Congfig) *Congfig {
@@ -66,22 +286,145 @@ func getConfig(cfg ...Congfig) *Congfig {
 	if len(cfg) > 0 {
 		userConfig := cfg[0]
 
+		config.App = userConfig.App
+		config.BufferSize = userConfig.BufferSize
+		if config.BufferSize > 0 && config.App == nil {
+			panic("logger: App is required to flush the buffered logs when the app shuts down")
+		}
+		if userConfig.FlushInterval > 0 {
+			config.FlushInterval = userConfig.FlushInterval
+		}
+		config.Preset = userConfig.Preset
 		if userConfig.Format != nil {
 			config.Format = userConfig.Format
+		} else {
+			format := presetFormat(config.Preset)
+			config.Format = &format
 		}
 		if userConfig.Output != nil {
 			config.Output = userConfig.Output
 		}
+		config.SampleRate = userConfig.SampleRate
+		config.Skip = userConfig.Skip
+		config.Style = userConfig.Style
+		if config.Preset == PresetJSON {
+			config.Style = StyleJSON
+		}
 	}
 
 	return config
 }
 
+// presetFormat returns the format of the preset.
+func presetFormat(preset Preset) string {
+	switch preset {
+	case PresetCommon:
+		return FormatCommon
+	case PresetCombined:
+		return FormatCombined
+	}
+
+	return FormatDefault
+}
+
 func getDefaultConfig() *Congfig {
-	defaultFormat := "{{.Method}} {{.Path}} {{.StatusCode}} {{.MilLatency}}ms"
+	defaultFormat := FormatDefault
 
 	return &Congfig{
-		Format: &defaultFormat,
-		Output: nil,
+		FlushInterval: defaultFlushInterval,
+		Format:        &defaultFormat,
+		Output:        nil,
+	}
+}
+
+// bufferedWriters holds the buffered writers of the outputs, the output is the app logger's
+// writer if it's not set in the config, so it may be different for the apps.
+type bufferedWriters struct {
+	interval time.Duration
+	mu       sync.Mutex
+	size     int
+	writers  map[io.Writer]*bufferedWriter
+}
+
+// get returns the buffered writer of the output.
+func (buffers *bufferedWriters) get(output io.Writer) *bufferedWriter {
+	buffers.mu.Lock()
+	defer buffers.mu.Unlock()
+
+	if buffers.writers == nil {
+		buffers.writers = make(map[io.Writer]*bufferedWriter)
+	}
+
+	writer, ok := buffers.writers[output]
+	if !ok {
+		writer = &bufferedWriter{
+			buf:      make([]byte, 0, buffers.size),
+			interval: buffers.interval,
+			output:   output,
+		}
+		buffers.writers[output] = writer
+	}
+
+	return writer
+}
+
+// flush writes the buffered logs of all outputs.
+func (buffers *bufferedWriters) flush() {
+	buffers.mu.Lock()
+	defer buffers.mu.Unlock()
+
+	for _, writer := range buffers.writers {
+		writer.Flush()
+	}
+}
+
+// bufferedWriter buffers the logs and writes them to the output when the buffer is full or
+// after the interval.
+type bufferedWriter struct {
+	buf      []byte
+	interval time.Duration
+	mu       sync.Mutex
+	output   io.Writer
+	timer    *time.Timer
+}
+
+// Write appends the log to the buffer, the buffer is flushed before appending if there is no
+// enough space.
+func (writer *bufferedWriter) Write(p []byte) (int, error) {
+	writer.mu.Lock()
+	defer writer.mu.Unlock()
+
+	if len(writer.buf)+len(p) > cap(writer.buf) {
+		writer.flushLocked()
+	}
+	if len(p) > cap(writer.buf) {
+		return writer.output.Write(p)
+	}
+
+	writer.buf = append(writer.buf, p...)
+	if writer.timer == nil {
+		writer.timer = time.AfterFunc(writer.interval, writer.Flush)
+	}
+
+	return len(p), nil
+}
+
+// Flush writes the buffered logs to the output.
+func (writer *bufferedWriter) Flush() {
+	writer.mu.Lock()
+	defer writer.mu.Unlock()
+
+	writer.flushLocked()
+}
+
+// flushLocked writes the buffered logs to the output, the caller should hold the lock.
+func (writer *bufferedWriter) flushLocked() {
+	if writer.timer != nil {
+		writer.timer.Stop()
+		writer.timer = nil
+	}
+	if len(writer.buf) > 0 {
+		writer.output.Write(writer.buf)
+		writer.buf = writer.buf[:0]
 	}
 }
@@ -0,0 +1,169 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ghosind/dolphin"
+)
+
+func testLogger(config Congfig, req *http.Request) *bytes.Buffer {
+	buf := new(bytes.Buffer)
+	var output io.Writer = buf
+	config.Output = &output
+
+	app := dolphin.New(nil)
+	app.Use(Logger(config), func(ctx *dolphin.Context) {
+		ctx.AddError(errors.New("something wrong"))
+		ctx.String("Hello", http.StatusInternalServerError)
+	})
+	app.ServeHTTP(httptest.NewRecorder(), req)
+
+	return buf
+}
+
+func TestLoggerTemplate(t *testing.T) {
+	buf := testLogger(Congfig{}, httptest.NewRequest(http.MethodGet, "/path?q=1", nil))
+	if line := buf.String(); !strings.HasPrefix(line, "GET /path?q=1 500 ") || !strings.HasSuffix(line, "ms\n") {
+		t.Errorf("Template log expect request line, actual %q", line)
+	}
+
+	format := "{{.Method}} {{.RequestSize}} {{.ResponseSize}}"
+	buf = testLogger(Congfig{Format: &format}, httptest.NewRequest(http.MethodPost, "/", strings.NewReader("body")))
+	if line := buf.String(); line != "POST 4 5\n" {
+		t.Errorf("Template log expect trailing newline, actual %q", line)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/path", nil)
+	req.SetBasicAuth("admin", "secret")
+	req.Header.Set("Referer", "https://example.com/")
+	req.Header.Set("User-Agent", "dolphin-test")
+	buf = testLogger(Congfig{Preset: PresetCombined}, req)
+	pattern := regexp.MustCompile(`^192\.0\.2\.1:1234 - admin \[\d{2}/\w{3}/\d{4}:\d{2}:\d{2}:\d{2} [+-]\d{4}\] "GET /path HTTP/1\.1" 500 5 "https://example\.com/" "dolphin-test"\n$`)
+	if !pattern.MatchString(buf.String()) {
+		t.Errorf("Combined log expect Combined Log Format, actual %q", buf.String())
+	}
+}
+
+func TestLoggerSkipAndSampling(t *testing.T) {
+	buf := new(bytes.Buffer)
+	var output io.Writer = buf
+
+	app := dolphin.New(nil)
+	app.Use(Logger(Congfig{
+		Output:     &output,
+		SampleRate: 1e-12,
+		Skip:       SkipPaths("/healthz"),
+	}), func(ctx *dolphin.Context) {
+		if ctx.Path() == "/error" {
+			ctx.String("Error", http.StatusInternalServerError)
+		}
+	})
+
+	for _, path := range []string{"/healthz", "/", "/error"} {
+		app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	if line := buf.String(); !strings.HasPrefix(line, "GET /error 500") || strings.Count(line, "\n") != 1 {
+		t.Errorf("Logs expect only the failed request, actual %q", line)
+	}
+}
+
+func TestLoggerBuffered(t *testing.T) {
+	buf := new(bytes.Buffer)
+	writer := &bufferedWriter{buf: make([]byte, 0, 16), interval: time.Hour, output: buf}
+
+	writer.Write([]byte("0123456789\n"))
+	if buf.Len() != 0 {
+		t.Errorf("Buffered log expect not written, actual %q", buf.String())
+	}
+
+	writer.Write([]byte("abcdef\n"))
+	if buf.String() != "0123456789\n" {
+		t.Errorf("Full buffer expect flushed, actual %q", buf.String())
+	}
+
+	writer.Flush()
+	if buf.String() != "0123456789\nabcdef\n" {
+		t.Errorf("Flush expect all logs written, actual %q", buf.String())
+	}
+}
+
+func TestLoggerFlushOnShutdown(t *testing.T) {
+	buf := new(bytes.Buffer)
+	var output io.Writer = buf
+
+	app := dolphin.New(nil)
+	app.Use(Logger(Congfig{App: app, BufferSize: 1024, FlushInterval: time.Hour, Output: &output}))
+
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if buf.Len() != 0 {
+		t.Errorf("Buffered log expect not written, actual %q", buf.String())
+	}
+
+	if err := app.Shutdown(); err != nil {
+		t.Errorf("Shutdown expect no error, actual %v", err)
+	}
+	if buf.Len() == 0 {
+		t.Error("Shutdown expect buffered logs flushed, actual not")
+	}
+}
+
+func TestLoggerBufferWithoutApp(t *testing.T) {
+	defer func() {
+		if p := recover(); p == nil {
+			t.Error("BufferSize without App expect panic, actual no panic")
+		}
+	}()
+
+	Logger(Congfig{BufferSize: 1024})
+}
+
+func TestLoggerJSON(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/path", strings.NewReader("body"))
+	req.Header.Set("User-Agent", "dolphin-test")
+	buf := testLogger(Congfig{Style: StyleJSON}, req)
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("JSON log expect valid JSON, actual %q", buf.String())
+	}
+
+	expected := map[string]any{
+		"level":      "ERROR",
+		"msg":        "request",
+		"method":     "POST",
+		"path":       "/path",
+		"status":     float64(500),
+		"bytes_in":   float64(4),
+		"bytes_out":  float64(5),
+		"user_agent": "dolphin-test",
+	}
+	for key, value := range expected {
+		if record[key] != value {
+			t.Errorf("JSON log %s expect %v, actual %v", key, value, record[key])
+		}
+	}
+	if _, ok := record["latency_us"].(float64); !ok {
+		t.Errorf("JSON log expect latency_us, actual %v", record["latency_us"])
+	}
+	if errs, ok := record["errors"].([]any); !ok || len(errs) != 1 || errs[0] != "something wrong" {
+		t.Errorf("JSON log expect errors, actual %v", record["errors"])
+	}
+}
+
+func TestLoggerLogfmt(t *testing.T) {
+	buf := testLogger(Congfig{Style: StyleLogfmt}, httptest.NewRequest(http.MethodGet, "/", nil))
+	if line := buf.String(); !strings.Contains(line, "msg=request method=GET") || !strings.Contains(line, "status=500") {
+		t.Errorf("Logfmt log expect key=value pairs, actual %q", line)
+	}
+}
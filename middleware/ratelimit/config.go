@@ -0,0 +1,82 @@
+package ratelimit
+
+import (
+	"time"
+
+	"github.com/ghosind/dolphin"
+)
+
+// Algorithm is the rate limiting algorithm of the in-memory store.
+type Algorithm int
+
+const (
+	// TokenBucket allows bursts up to the burst size, and refills the tokens at the steady
+	// rate of Limit per Window.
+	TokenBucket Algorithm = iota
+	// SlidingWindow limits the requests in the sliding window by weighting the count of the
+	// previous fixed window.
+	SlidingWindow
+)
+
+// KeyFunc returns the key to identify the client of the request, the requests with the same
+// key share the same limit.
+type KeyFunc func(*dolphin.Context) string
+
+// LimitHandler is the handler that will be triggered when the request exceeds the limit.
+type LimitHandler func(*dolphin.Context, Result)
+
+// Config is the rate limit middleware config.
+type Config struct {
+	// Algorithm is the algorithm of the in-memory store, it's ignored if Store is set. Default
+	// TokenBucket.
+	Algorithm Algorithm
+	// Burst is the bucket size of the token bucket algorithm, default Limit.
+	Burst int
+	// Handler is the handler that will be triggered when the request exceeds the limit. It'll
+	// respond 429 (Too Many Requests) if the handler is not set.
+	Handler LimitHandler
+	// KeyFunc returns the key of the request, default KeyByIP.
+	KeyFunc KeyFunc
+	// Limit is the maximum number of requests in the window, default 60.
+	Limit int
+	// Skip returns true if the request should not be limited, like health checks.
+	Skip func(*dolphin.Context) bool
+	// Store is the store of the limiter states, default an in-memory store with the algorithm.
+	Store Store
+	// Window is the time window of the limit, default one minute.
+	Window time.Duration
+}
+
+// DefaultConfig is the default rate limit middleware config.
+var DefaultConfig = Config{
+	Algorithm: TokenBucket,
+	Handler:   defaultHandler,
+	KeyFunc:   KeyByIP,
+	Limit:     60,
+	Window:    time.Minute,
+}
+
+func getConfig(config ...Config) Config {
+	if len(config) < 1 {
+		return DefaultConfig
+	}
+
+	cfg := config[0]
+	if cfg.Handler == nil {
+		cfg.Handler = DefaultConfig.Handler
+	}
+	if cfg.KeyFunc == nil {
+		cfg.KeyFunc = DefaultConfig.KeyFunc
+	}
+	if cfg.Limit <= 0 {
+		cfg.Limit = DefaultConfig.Limit
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = DefaultConfig.Window
+	}
+	if cfg.Burst <= 0 {
+		cfg.Burst = cfg.Limit
+	}
+
+	return cfg
+}
@@ -0,0 +1,97 @@
+package ratelimit
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ghosind/dolphin"
+)
+
+// RateLimit returns a middleware that limits the request rate by the key of the requests. It
+// sets the "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", and "RateLimit-Policy"
+// headers, and triggers the handler with "Retry-After" header if the request exceeds the
+// limit. Both "RateLimit-Limit" and "RateLimit-Policy" report the quota of the store result,
+// it's the burst size for the token bucket algorithm.
+//
+// The requests are allowed if the store returns an error, so the service is still available
+// when the external store is unavailable.
+func RateLimit(config ...Config) dolphin.HandlerFunc {
+	cfg := getConfig(config...)
+
+	store := cfg.Store
+	if store == nil {
+		switch cfg.Algorithm {
+		case SlidingWindow:
+			store = NewSlidingWindowStore(cfg.Limit, cfg.Window)
+		default:
+			store = NewTokenBucketStore(cfg.Limit, cfg.Window, cfg.Burst)
+		}
+	}
+
+	window := ";w=" + strconv.FormatInt(int64(cfg.Window.Seconds()), 10)
+
+	return func(ctx *dolphin.Context) {
+		if cfg.Skip != nil && cfg.Skip(ctx) {
+			ctx.Next()
+			return
+		}
+
+		result, err := store.Take(cfg.KeyFunc(ctx))
+		if err != nil {
+			ctx.Log("Failed to take rate limit: %v\n", err)
+			ctx.Next()
+			return
+		}
+
+		limit := strconv.Itoa(result.Limit)
+		ctx.SetHeader("RateLimit-Limit", limit)
+		ctx.SetHeader("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		ctx.SetHeader("RateLimit-Reset", formatSeconds(result.Reset))
+		ctx.SetHeader("RateLimit-Policy", limit+window)
+
+		if !result.Allowed {
+			ctx.SetHeader("Retry-After", formatSeconds(result.RetryAfter))
+			cfg.Handler(ctx, result)
+			ctx.Abort()
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// KeyByIP returns the client IP address as the key.
+func KeyByIP(ctx *dolphin.Context) string {
+	ip := ctx.IP()
+	// The remote address may contain the port, the requests from the same host should share
+	// the same limit.
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		return host
+	}
+
+	return ip
+}
+
+// KeyByHeader returns a key function that uses the value of the request header as the key,
+// like the API key header. It'll fall back to the client IP address if the header is not set.
+func KeyByHeader(name string) KeyFunc {
+	return func(ctx *dolphin.Context) string {
+		if val := ctx.Header(name); val != "" {
+			return name + ":" + val
+		}
+
+		return KeyByIP(ctx)
+	}
+}
+
+// formatSeconds returns the duration in seconds, it's rounded up to avoid retrying too early.
+func formatSeconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
+
+func defaultHandler(ctx *dolphin.Context, result Result) {
+	ctx.String("Too Many Requests", http.StatusTooManyRequests)
+}
@@ -0,0 +1,113 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ghosind/dolphin"
+)
+
+func TestTokenBucketStore(t *testing.T) {
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := NewTokenBucketStore(1, time.Second, 3)
+	store.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		result, _ := store.Take("key")
+		if !result.Allowed || result.Remaining != 2-i {
+			t.Errorf("Request %d expect allowed with %d remaining, actual %+v", i, 2-i, result)
+		}
+	}
+
+	result, _ := store.Take("key")
+	if result.Allowed || result.RetryAfter != time.Second {
+		t.Errorf("Request exceeds burst expect denied with 1s retry, actual %+v", result)
+	}
+
+	if result, _ := store.Take("other"); !result.Allowed {
+		t.Errorf("Request with other key expect allowed, actual %+v", result)
+	}
+
+	now = now.Add(time.Second)
+	if result, _ := store.Take("key"); !result.Allowed {
+		t.Errorf("Request after refilling expect allowed, actual %+v", result)
+	}
+}
+
+func TestSlidingWindowStore(t *testing.T) {
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := NewSlidingWindowStore(2, time.Minute)
+	store.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if result, _ := store.Take("key"); !result.Allowed {
+			t.Errorf("Request %d expect allowed, actual %+v", i, result)
+		}
+	}
+
+	result, _ := store.Take("key")
+	if result.Allowed || result.RetryAfter != time.Minute {
+		t.Errorf("Request exceeds limit expect denied with 1m retry, actual %+v", result)
+	}
+
+	// The previous window is weighted by 75%, so 1.5 requests are counted.
+	now = now.Add(75 * time.Second)
+	if result, _ := store.Take("key"); !result.Allowed {
+		t.Errorf("Request in the next window expect allowed, actual %+v", result)
+	}
+
+	// 2 * (2/3) + 1 requests are counted, it should wait for the weight decreasing to 50%.
+	now = now.Add(5 * time.Second)
+	if result, _ := store.Take("key"); result.Allowed || result.RetryAfter != 10*time.Second {
+		t.Errorf("Request exceeds limit expect denied with 10s retry, actual %+v", result)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	app := dolphin.New(nil)
+	app.Use(RateLimit(Config{
+		Burst:   2,
+		Limit:   1,
+		KeyFunc: KeyByHeader("X-API-Key"),
+		Skip: func(ctx *dolphin.Context) bool {
+			return ctx.Path() == "/health"
+		},
+	}), func(ctx *dolphin.Context) {
+		ctx.String("OK")
+	})
+
+	request := func(path, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("X-API-Key", key)
+		rec := httptest.NewRecorder()
+		app.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := request("/", "a")
+	if rec.Code != http.StatusOK || rec.Header().Get("RateLimit-Remaining") != "1" {
+		t.Errorf("First request expect 200 with 1 remaining, actual %d %v", rec.Code, rec.Header())
+	}
+	if limit, policy := rec.Header().Get("RateLimit-Limit"), rec.Header().Get("RateLimit-Policy"); limit != "2" || policy != "2;w=60" {
+		t.Errorf("First request expect limit \"2\" and policy \"2;w=60\", actual %q %q", limit, policy)
+	}
+
+	rec = request("/", "a")
+	if rec.Code != http.StatusOK || rec.Header().Get("RateLimit-Remaining") != "0" {
+		t.Errorf("Second request expect 200 with 0 remaining, actual %d %v", rec.Code, rec.Header())
+	}
+
+	rec = request("/", "a")
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "60" {
+		t.Errorf("Third request expect 429 with Retry-After 60, actual %d %v", rec.Code, rec.Header())
+	}
+
+	if rec = request("/", "b"); rec.Code != http.StatusOK {
+		t.Errorf("Request with other key expect 200, actual %d", rec.Code)
+	}
+	if rec = request("/health", "a"); rec.Code != http.StatusOK {
+		t.Errorf("Skipped request expect 200, actual %d", rec.Code)
+	}
+}
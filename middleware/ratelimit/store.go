@@ -0,0 +1,247 @@
+package ratelimit
+
+import (
+	"hash/fnv"
+	"math"
+	"sync"
+	"time"
+)
+
+// shardCount is the number of the shards of the in-memory stores.
+const shardCount = 32
+
+// Result is the result of taking a request from the limiter.
+type Result struct {
+	// Allowed indicates whether the request is allowed.
+	Allowed bool
+	// Limit is the maximum number of requests in the window, it's the burst size for the token
+	// bucket algorithm. It's reported as the quota of the rate limit headers.
+	Limit int
+	// Remaining is the number of requests that can be made before exceeding the limit.
+	Remaining int
+	// Reset is the duration until the limit is fully restored.
+	Reset time.Duration
+	// RetryAfter is the duration to wait before the next request is allowed, it's zero if the
+	// request is allowed.
+	RetryAfter time.Duration
+}
+
+// Store is the storage of the limiter states. It can be implemented with the external
+// backends like Redis to share the limits across instances.
+type Store interface {
+	// Take takes a request for the key, and returns the limit result.
+	Take(key string) (Result, error)
+}
+
+// memoryShard is a shard of the in-memory store.
+type memoryShard[T any] struct {
+	mu        sync.Mutex
+	entries   map[string]*T
+	lastSweep time.Time
+}
+
+// memoryStore is the base of the in-memory stores, it shards the entries by the key hash to
+// reduce the lock contention, and removes the expired entries periodically.
+type memoryStore[T any] struct {
+	shards  [shardCount]*memoryShard[T]
+	expired func(entry *T, now time.Time) bool
+	now     func() time.Time
+	ttl     time.Duration
+}
+
+// newMemoryStore creates a new in-memory store.
+func newMemoryStore[T any](ttl time.Duration, expired func(*T, time.Time) bool) *memoryStore[T] {
+	store := &memoryStore[T]{
+		expired: expired,
+		now:     time.Now,
+		ttl:     ttl,
+	}
+
+	for i := range store.shards {
+		store.shards[i] = &memoryShard[T]{
+			entries: make(map[string]*T),
+		}
+	}
+
+	return store
+}
+
+// update calls the function with the entry of the key in the lock of its shard.
+func (store *memoryStore[T]) update(key string, fn func(entry *T, now time.Time) Result) Result {
+	hash := fnv.New32a()
+	hash.Write([]byte(key))
+	shard := store.shards[hash.Sum32()%shardCount]
+
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	now := store.now()
+	if now.Sub(shard.lastSweep) > store.ttl {
+		for k, entry := range shard.entries {
+			if store.expired(entry, now) {
+				delete(shard.entries, k)
+			}
+		}
+		shard.lastSweep = now
+	}
+
+	entry, ok := shard.entries[key]
+	if !ok {
+		entry = new(T)
+		shard.entries[key] = entry
+	}
+
+	return fn(entry, now)
+}
+
+// tokenBucket is the state of a token bucket.
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// TokenBucketStore is an in-memory store with the token bucket algorithm.
+type TokenBucketStore struct {
+	*memoryStore[tokenBucket]
+	burst int
+	limit int
+	rate  float64
+}
+
+// NewTokenBucketStore creates an in-memory token bucket store, the buckets have the burst
+// size capacity, and are refilled at the rate of limit per window.
+func NewTokenBucketStore(limit int, window time.Duration, burst int) *TokenBucketStore {
+	if burst <= 0 {
+		burst = limit
+	}
+
+	store := &TokenBucketStore{
+		burst: burst,
+		limit: limit,
+		rate:  float64(limit) / window.Seconds(),
+	}
+	fillTime := time.Duration(float64(burst) / store.rate * float64(time.Second))
+
+	store.memoryStore = newMemoryStore(fillTime, func(bucket *tokenBucket, now time.Time) bool {
+		return now.Sub(bucket.last) >= fillTime
+	})
+
+	return store
+}
+
+// Take takes a token from the bucket of the key.
+func (store *TokenBucketStore) Take(key string) (Result, error) {
+	return store.update(key, func(bucket *tokenBucket, now time.Time) Result {
+		if bucket.last.IsZero() {
+			bucket.tokens = float64(store.burst)
+		} else {
+			elapsed := now.Sub(bucket.last).Seconds()
+			bucket.tokens = math.Min(float64(store.burst), bucket.tokens+elapsed*store.rate)
+		}
+		bucket.last = now
+
+		result := Result{Limit: store.burst}
+		if bucket.tokens >= 1 {
+			bucket.tokens--
+			result.Allowed = true
+		} else {
+			result.RetryAfter = store.duration(1 - bucket.tokens)
+		}
+
+		result.Remaining = int(bucket.tokens)
+		result.Reset = store.duration(float64(store.burst) - bucket.tokens)
+
+		return result
+	}), nil
+}
+
+// duration returns the duration to refill the number of tokens.
+func (store *TokenBucketStore) duration(tokens float64) time.Duration {
+	return time.Duration(math.Ceil(tokens / store.rate * float64(time.Second)))
+}
+
+// slidingWindow is the state of a sliding window.
+type slidingWindow struct {
+	start    time.Time
+	current  int
+	previous int
+}
+
+// SlidingWindowStore is an in-memory store with the sliding window algorithm.
+type SlidingWindowStore struct {
+	*memoryStore[slidingWindow]
+	limit  int
+	window time.Duration
+}
+
+// NewSlidingWindowStore creates an in-memory sliding window store that allows limit requests
+// in the window.
+func NewSlidingWindowStore(limit int, window time.Duration) *SlidingWindowStore {
+	store := &SlidingWindowStore{
+		limit:  limit,
+		window: window,
+	}
+
+	store.memoryStore = newMemoryStore(window, func(w *slidingWindow, now time.Time) bool {
+		return now.Sub(w.start) >= 2*window
+	})
+
+	return store
+}
+
+// Take takes a request from the sliding window of the key.
+func (store *SlidingWindowStore) Take(key string) (Result, error) {
+	return store.update(key, func(w *slidingWindow, now time.Time) Result {
+		start := now.Truncate(store.window)
+		switch {
+		case w.start.IsZero() || start.Sub(w.start) >= 2*store.window:
+			w.previous, w.current = 0, 0
+		case start.After(w.start):
+			w.previous, w.current = w.current, 0
+		}
+		w.start = start
+
+		elapsed := now.Sub(start)
+		weight := 1 - float64(elapsed)/float64(store.window)
+		count := float64(w.previous)*weight + float64(w.current)
+
+		result := Result{Limit: store.limit}
+		if count < float64(store.limit) {
+			w.current++
+			count++
+			result.Allowed = true
+		} else {
+			result.RetryAfter = store.retryAfter(w, elapsed)
+		}
+
+		result.Remaining = store.limit - int(math.Ceil(count))
+		if result.Remaining < 0 {
+			result.Remaining = 0
+		}
+		result.Reset = store.window - elapsed
+		if w.current > 0 {
+			// The requests in the current window are weighted in the next window.
+			result.Reset += store.window
+		}
+
+		return result
+	}), nil
+}
+
+// retryAfter returns the duration until the weighted count is less than the limit.
+func (store *SlidingWindowStore) retryAfter(w *slidingWindow, elapsed time.Duration) time.Duration {
+	if w.current >= store.limit {
+		// Wait for the next window, the current count will be weighted as the previous count.
+		nextWeight := 1 - float64(store.limit)/float64(w.current)
+		return store.window - elapsed + time.Duration(nextWeight*float64(store.window))
+	}
+
+	// Wait for the previous count weight to decrease.
+	weight := float64(store.limit-w.current) / float64(w.previous)
+	target := time.Duration((1 - weight) * float64(store.window))
+
+	if target <= elapsed {
+		return time.Millisecond
+	}
+	return target - elapsed
+}
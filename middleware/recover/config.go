@@ -5,6 +5,8 @@ type Config struct {
 	// Handler is the handler that will be trigged when catching some panics.
 	// It'll return a 500 error if the handler is not set.
 	Handler RecoverHandler
+	// LogStack logs the panics with the stack traces by the app structured logger.
+	LogStack bool
 }
 
 // DefaultConfig is the default recover middleware config.
@@ -17,5 +19,10 @@ func getConfig(config ...Config) Config {
 		return DefaultConfig
 	}
 
-	return config[0]
+	cfg := config[0]
+	if cfg.Handler == nil {
+		cfg.Handler = DefaultConfig.Handler
+	}
+
+	return cfg
 }
@@ -1,17 +1,26 @@
 package recover
 
 import (
+	"errors"
+	"fmt"
+	"net"
 	"net/http"
+	"os"
 	"runtime/debug"
+	"strings"
+	"syscall"
 
 	"github.com/ghosind/dolphin"
 )
 
 type PanicError struct {
-	// Err is the error that caused the panic.
+	// Err is the error that caused the panic, it's created from the panic value if the value is
+	// not an error.
 	Err error
 	// StackTrace is the stack trace of the panic.
 	StackTrace []byte
+	// Value is the original value that passed to panic.
+	Value any
 }
 
 // Error returns panic error message.
@@ -19,31 +28,101 @@ func (err PanicError) Error() string {
 	return err.Err.Error()
 }
 
+// Unwrap returns the error that caused the panic.
+func (err PanicError) Unwrap() error {
+	return err.Err
+}
+
 type RecoverHandler func(*dolphin.Context, *PanicError)
 
 // Recover returns a middleware that recovers from panics, and it'll return 500 error default.
+//
+// The panics caused by the broken connections (like broken pipe or connection reset by peer)
+// abort the handler chain without calling the handler, because the response can't be sent to
+// the client. The http.ErrAbortHandler panics are re-panicked to abort the request like
+// net/http does.
 func Recover(config ...Config) dolphin.HandlerFunc {
 	cfg := getConfig(config...)
 
 	return func(ctx *dolphin.Context) {
 		defer func() {
-			if err := recover(); err != nil {
-				e := PanicError{
-					Err:        err.(error),
-					StackTrace: debug.Stack(),
-				}
+			val := recover()
+			if val == nil {
+				return
+			}
+
+			if err, ok := val.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+				panic(val)
+			}
 
-				if cfg.Handler != nil {
-					cfg.Handler(ctx, &e)
+			e := PanicError{
+				Err:        toError(val),
+				StackTrace: debug.Stack(),
+				Value:      val,
+			}
+			ctx.AddError(&e)
+
+			if isBrokenPipe(e.Err) {
+				if cfg.LogStack {
+					ctx.Logger().Warn("connection broken", "error", e.Err)
 				}
+				ctx.Abort()
+				return
+			}
+
+			if cfg.LogStack {
+				ctx.Logger().Error("panic recovered", "error", e.Err, "stack", string(e.StackTrace))
 			}
+
+			cfg.Handler(ctx, &e)
 		}()
 
 		ctx.Next()
 	}
 }
 
+// toError returns the panic value as an error.
+func toError(val any) error {
+	if err, ok := val.(error); ok {
+		return err
+	}
+
+	return fmt.Errorf("%v", val)
+}
+
+// isBrokenPipe reports whether the error is caused by the client closing the connection.
+func isBrokenPipe(err error) bool {
+	if errors.Is(err, syscall.EPIPE) || errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+
+	var opErr *net.OpError
+	if !errors.As(err, &opErr) {
+		return false
+	}
+
+	var syscallErr *os.SyscallError
+	if errors.As(opErr.Err, &syscallErr) {
+		msg := strings.ToLower(syscallErr.Error())
+		return strings.Contains(msg, "broken pipe") || strings.Contains(msg, "connection reset by peer")
+	}
+
+	return false
+}
+
+// defaultHandler discards the partial response that written by the handlers, and returns a 500
+// (Internal Server Error) response, or a problem details document if the app enables the problem
+// details. The response is sent after the handler chain, so the partial response has not been
+// sent to the client.
 func defaultHandler(ctx *dolphin.Context, err *PanicError) {
-	ctx.String("Internal Server Error", http.StatusInternalServerError)
+	ctx.Response.ResetBody()
+	ctx.Response.DelHeader("Content-Encoding")
+	ctx.Response.DelHeader("Content-Length")
+	ctx.Response.DelHeader("Content-Range")
+	if ctx.ProblemDetailsEnabled() {
+		ctx.Problem(dolphin.NewProblem(http.StatusInternalServerError))
+	} else {
+		ctx.String("Internal Server Error", http.StatusInternalServerError)
+	}
 	ctx.Abort()
 }
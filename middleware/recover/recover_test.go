@@ -0,0 +1,109 @@
+package recover
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/ghosind/dolphin"
+	"github.com/ghosind/dolphin/internal/dolphintest"
+)
+
+func TestRecoverPanicValues(t *testing.T) {
+	for _, val := range []any{"boom", errors.New("boom"), 42, struct{ Name string }{"boom"}} {
+		var recovered *PanicError
+
+		rec := dolphintest.Request(httptest.NewRequest(http.MethodGet, "/", nil), Recover(Config{
+			Handler: func(ctx *dolphin.Context, err *PanicError) {
+				recovered = err
+				defaultHandler(ctx, err)
+			},
+		}), func(ctx *dolphin.Context) {
+			ctx.String("partial")
+			ctx.SetHeader("Content-Length", "7")
+			panic(val)
+		})
+
+		if rec.Code != http.StatusInternalServerError || rec.Body.String() != "Internal Server Error" {
+			t.Errorf("Panic %v expect 500 without partial body, actual %d %q", val, rec.Code, rec.Body.String())
+		}
+		if recovered == nil || recovered.Value != val || recovered.Err == nil {
+			t.Errorf("Panic %v expect recovered with value, actual %+v", val, recovered)
+		}
+	}
+}
+
+func TestRecoverAbortHandler(t *testing.T) {
+	defer func() {
+		if val := recover(); val != http.ErrAbortHandler {
+			t.Errorf("http.ErrAbortHandler expect re-panicked, actual %v", val)
+		}
+	}()
+
+	dolphintest.Request(httptest.NewRequest(http.MethodGet, "/", nil), Recover(), func(ctx *dolphin.Context) {
+		panic(http.ErrAbortHandler)
+	})
+}
+
+func TestRecoverBrokenPipe(t *testing.T) {
+	called := false
+	err := &net.OpError{Op: "write", Net: "tcp", Err: os.NewSyscallError("write", syscall.EPIPE)}
+
+	rec := dolphintest.Request(httptest.NewRequest(http.MethodGet, "/", nil), Recover(Config{
+		Handler: func(ctx *dolphin.Context, err *PanicError) {
+			called = true
+		},
+	}), func(ctx *dolphin.Context) {
+		panic(err)
+	})
+
+	if called || rec.Code != http.StatusOK {
+		t.Errorf("Broken pipe expect handler not called, actual %v %d", called, rec.Code)
+	}
+}
+
+func TestRecoverLogStack(t *testing.T) {
+	buf := new(bytes.Buffer)
+	app := dolphin.New(&dolphin.Config{StructuredLogger: slog.New(slog.NewTextHandler(buf, nil))})
+	app.Use(Recover(Config{LogStack: true}), func(ctx *dolphin.Context) {
+		panic("boom")
+	})
+
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("Panic expect 500, actual %d", rec.Code)
+	}
+	if log := buf.String(); !strings.Contains(log, "panic recovered") || !strings.Contains(log, "error=boom") ||
+		!strings.Contains(log, "runtime/debug.Stack") {
+		t.Errorf("Log expect panic with stack trace, actual %q", log)
+	}
+}
+
+func TestRecoverProblemDetails(t *testing.T) {
+	app := dolphin.New(&dolphin.Config{ProblemDetails: true})
+	app.Use(Recover(), func(ctx *dolphin.Context) {
+		panic("boom")
+	})
+
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("Panic expect 500, actual %d", rec.Code)
+	}
+	if contentType := rec.Header().Get("Content-Type"); contentType != dolphin.ProblemContentType {
+		t.Errorf("Content type expect %q, actual %q", dolphin.ProblemContentType, contentType)
+	}
+	if body := rec.Body.String(); body != `{"status":500,"title":"Internal Server Error","type":"about:blank"}` {
+		t.Errorf("Body expect problem details, actual %q", body)
+	}
+}
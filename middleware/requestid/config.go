@@ -0,0 +1,52 @@
+package requestid
+
+// Generator is the function to generate the request IDs.
+type Generator func() string
+
+// Config is the request ID middleware config.
+type Config struct {
+	// Generator generates the ID if the request has no valid ID, it can be UUIDv4, ULID, or a
+	// custom function. Default UUIDv4.
+	Generator Generator
+	// Header is the header to read and write the request ID, default "X-Request-ID".
+	Header string
+	// IgnoreIncoming ignores the ID in the request header and always generates a new ID, it
+	// should be set if the clients are not trusted.
+	IgnoreIncoming bool
+	// MaxLength is the maximum length of the incoming ID, default 128.
+	MaxLength int
+	// Validator reports whether the incoming ID is valid, the invalid ID is replaced with a
+	// generated ID. The default validator allows the alphanumeric characters and "-", "_", ".",
+	// ":", "+", "/", and "=".
+	Validator func(id string) bool
+}
+
+// DefaultConfig is the default request ID middleware config.
+var DefaultConfig = Config{
+	Generator: UUIDv4,
+	Header:    "X-Request-ID",
+	MaxLength: 128,
+	Validator: isValidID,
+}
+
+func getConfig(config ...Config) Config {
+	if len(config) < 1 {
+		return DefaultConfig
+	}
+
+	cfg := config[0]
+	if cfg.Generator == nil {
+		cfg.Generator = DefaultConfig.Generator
+	}
+	if cfg.Header == "" {
+		cfg.Header = DefaultConfig.Header
+	}
+	if cfg.MaxLength <= 0 {
+		cfg.MaxLength = DefaultConfig.MaxLength
+	}
+	if cfg.Validator == nil {
+		cfg.Validator = DefaultConfig.Validator
+	}
+
+	return cfg
+}
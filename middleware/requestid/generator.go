@@ -0,0 +1,60 @@
+package requestid
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"time"
+)
+
+// crockfordAlphabet is the Crockford's Base32 alphabet that used by ULID.
+const crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// UUIDv4 generates a random UUID (version 4) like "6ba7b810-9dad-41d1-80b4-00c04fd430c8".
+func UUIDv4() string {
+	var uuid [16]byte
+	readRandom(uuid[:])
+	uuid[6] = uuid[6]&0x0f | 0x40 // Version 4
+	uuid[8] = uuid[8]&0x3f | 0x80 // Variant RFC 4122
+
+	buf := make([]byte, 36)
+	hex.Encode(buf[0:8], uuid[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], uuid[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], uuid[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], uuid[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], uuid[10:])
+
+	return string(buf)
+}
+
+// ULID generates a Universally Unique Lexicographically Sortable Identifier like
+// "01ARZ3NDEKTSV4RRFFQ69G5FAV", the IDs are sortable by the generated time in milliseconds.
+func ULID() string {
+	var id [16]byte
+	binary.BigEndian.PutUint64(id[:8], uint64(time.Now().UnixMilli())<<16)
+	readRandom(id[6:])
+
+	// Encode the 128 bits into 26 characters, 5 bits per character from the most significant
+	// bits, and the first character has only 3 bits.
+	buf := make([]byte, 26)
+	hi := binary.BigEndian.Uint64(id[:8])
+	lo := binary.BigEndian.Uint64(id[8:])
+	for i := 25; i >= 0; i-- {
+		buf[i] = crockfordAlphabet[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+
+	return string(buf)
+}
+
+// readRandom fills the buffer with the random bytes.
+func readRandom(buf []byte) {
+	if _, err := rand.Read(buf); err != nil {
+		panic(err)
+	}
+}
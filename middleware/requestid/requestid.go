@@ -0,0 +1,50 @@
+package requestid
+
+import (
+	"github.com/ghosind/dolphin"
+)
+
+// RequestID returns a middleware that assigns an identifier to each request. It uses the ID in
+// the request header if it's valid, or generates a new ID. The ID is stored in the context, so it
+// can be got by ctx.RequestID(), and it's also set to the response header.
+func RequestID(config ...Config) dolphin.HandlerFunc {
+	cfg := getConfig(config...)
+
+	return func(ctx *dolphin.Context) {
+		id := ""
+		if !cfg.IgnoreIncoming {
+			id = ctx.Header(cfg.Header)
+			if len(id) > cfg.MaxLength || !cfg.Validator(id) {
+				id = ""
+			}
+		}
+		if id == "" {
+			id = cfg.Generator()
+		}
+
+		ctx.SetRequestID(id)
+		ctx.SetHeader(cfg.Header, id)
+
+		ctx.Next()
+	}
+}
+
+// isValidID reports whether the ID is not empty and contains only the alphanumeric characters
+// and "-", "_", ".", ":", "+", "/", and "=".
+func isValidID(id string) bool {
+	if id == "" {
+		return false
+	}
+
+	for i := 0; i < len(id); i++ {
+		c := id[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':', c == '+', c == '/', c == '=':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
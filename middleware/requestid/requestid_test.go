@@ -0,0 +1,92 @@
+package requestid
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/ghosind/dolphin"
+	"github.com/ghosind/dolphin/internal/dolphintest"
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func TestRequestID(t *testing.T) {
+	rec, id := dolphintest.Capture(httptest.NewRequest(http.MethodGet, "/", nil), RequestID(), (*dolphin.Context).RequestID)
+	if !uuidPattern.MatchString(id) {
+		t.Errorf("Generated ID expect UUIDv4, actual %q", id)
+	}
+	if header := rec.Header().Get("X-Request-ID"); header != id {
+		t.Errorf("Response header expect %q, actual %q", id, header)
+	}
+
+	cases := []struct {
+		incoming string
+		accepted bool
+	}{
+		{"abc-123_DEF.4:5", true},
+		{"dGVzdA==", true},
+		{"", false},
+		{"has space", false},
+		{"new\nline", false},
+		{"100%", false},
+		{strings.Repeat("a", 129), false},
+	}
+
+	for _, c := range cases {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-Request-ID", c.incoming)
+
+		_, id := dolphintest.Capture(req, RequestID(), (*dolphin.Context).RequestID)
+		if accepted := id == c.incoming; accepted != c.accepted {
+			t.Errorf("Incoming ID %q expect accepted %v, actual %q", c.incoming, c.accepted, id)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Trace-ID", "trusted")
+	rec, id = dolphintest.Capture(req, RequestID(Config{
+		Generator:      ULID,
+		Header:         "X-Trace-ID",
+		IgnoreIncoming: true,
+	}), (*dolphin.Context).RequestID)
+	if len(id) != 26 || id == "trusted" || rec.Header().Get("X-Trace-ID") != id {
+		t.Errorf("Ignored incoming ID expect new ULID, actual %q", id)
+	}
+}
+
+func TestULID(t *testing.T) {
+	pattern := regexp.MustCompile(`^[0-7][0-9A-HJKMNP-TV-Z]{25}$`)
+	prev := ""
+
+	for i := 0; i < 100; i++ {
+		id := ULID()
+		if !pattern.MatchString(id) {
+			t.Fatalf("ULID expect Crockford's Base32, actual %q", id)
+		}
+		if id[:10] < prev {
+			t.Fatalf("ULID timestamp expect non-decreasing, actual %q after %q", id[:10], prev)
+		}
+		prev = id[:10]
+	}
+}
+
+func TestRequestIDLog(t *testing.T) {
+	buf := new(bytes.Buffer)
+	app := dolphin.New(&dolphin.Config{Logger: log.New(buf, "", 0)})
+	app.Use(RequestID(), func(ctx *dolphin.Context) {
+		ctx.Log("handled\n")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Request-ID", "req-1")
+	app.ServeHTTP(httptest.NewRecorder(), req)
+
+	if buf.String() != "[req-1] handled\n" {
+		t.Errorf("Log expect prefixed with request ID, actual %q", buf.String())
+	}
+}
@@ -0,0 +1,112 @@
+package secure
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/ghosind/dolphin"
+)
+
+// Disabled is the value to disable a header that has a default value.
+const Disabled = "-"
+
+// Config is the secure middleware config.
+type Config struct {
+	// AllowedHosts is the list of the hosts that the HTTP requests can be redirected to if
+	// HTTPSHost is not set, the requests with other hosts get a 400 (Bad Request) response. All
+	// hosts are allowed if it's empty.
+	AllowedHosts []string
+	// ContentSecurityPolicy is the Content-Security-Policy of the responses, the header is not
+	// set if it's nil. The frame-ancestors directive is added by FrameOptions if it's not set.
+	ContentSecurityPolicy *CSP
+	// ContentSecurityPolicyReportOnly sends the policy by the
+	// Content-Security-Policy-Report-Only header instead.
+	ContentSecurityPolicyReportOnly bool
+	// ContentTypeNosniff is the value of the X-Content-Type-Options header, default "nosniff".
+	ContentTypeNosniff string
+	// CrossOriginEmbedderPolicy is the value of the Cross-Origin-Embedder-Policy header, like
+	// "require-corp". The header is not set if it's empty.
+	CrossOriginEmbedderPolicy string
+	// CrossOriginOpenerPolicy is the value of the Cross-Origin-Opener-Policy header, default
+	// "same-origin".
+	CrossOriginOpenerPolicy string
+	// CrossOriginResourcePolicy is the value of the Cross-Origin-Resource-Policy header, default
+	// "same-origin".
+	CrossOriginResourcePolicy string
+	// FrameOptions is the value of the X-Frame-Options header, it can be "DENY" or
+	// "SAMEORIGIN". Default "DENY".
+	FrameOptions string
+	// HSTSIncludeSubdomains adds the includeSubDomains directive to the
+	// Strict-Transport-Security header.
+	HSTSIncludeSubdomains bool
+	// HSTSMaxAge is the max-age of the Strict-Transport-Security header, default 365 days. The
+	// header is only set for the HTTPS requests, and it's disabled if HSTSMaxAge is negative.
+	HSTSMaxAge time.Duration
+	// HSTSPreload adds the preload directive to the Strict-Transport-Security header.
+	HSTSPreload bool
+	// HTTPSRedirect redirects the HTTP requests to HTTPS.
+	HTTPSRedirect bool
+	// HTTPSHost is the host of the HTTPS redirect location without the port, default the request
+	// host with its port removed. The request host is sent by the client, so it should be set,
+	// or the request hosts should be limited by AllowedHosts, in the production environment.
+	HTTPSHost string
+	// HTTPSPort is the port of the HTTPS redirect location, default 443.
+	HTTPSPort int
+	// PermissionsPolicy is the value of the Permissions-Policy header, like
+	// "camera=(), microphone=()". The header is not set if it's empty.
+	PermissionsPolicy string
+	// RedirectStatusCode is the status code of the HTTPS redirect, default 301 (Moved
+	// Permanently).
+	RedirectStatusCode int
+	// ReferrerPolicy is the value of the Referrer-Policy header, default
+	// "strict-origin-when-cross-origin".
+	ReferrerPolicy string
+	// Skip returns true if the request should not be handled by the middleware.
+	Skip func(*dolphin.Context) bool
+	// TrustedProxies is the list of the IP addresses or the CIDRs of the trusted proxies, the
+	// X-Forwarded-Proto, X-Forwarded-Ssl, and Forwarded headers are only used to detect HTTPS
+	// requests from the trusted proxies.
+	TrustedProxies []string
+}
+
+// DefaultConfig is the default secure middleware config.
+var DefaultConfig = Config{
+	ContentTypeNosniff:        "nosniff",
+	CrossOriginOpenerPolicy:   "same-origin",
+	CrossOriginResourcePolicy: "same-origin",
+	FrameOptions:              "DENY",
+	HSTSMaxAge:                365 * 24 * time.Hour,
+	RedirectStatusCode:        http.StatusMovedPermanently,
+	ReferrerPolicy:            "strict-origin-when-cross-origin",
+}
+
+func getConfig(config ...Config) Config {
+	if len(config) < 1 {
+		return DefaultConfig
+	}
+
+	cfg := config[0]
+	if cfg.ContentTypeNosniff == "" {
+		cfg.ContentTypeNosniff = DefaultConfig.ContentTypeNosniff
+	}
+	if cfg.CrossOriginOpenerPolicy == "" {
+		cfg.CrossOriginOpenerPolicy = DefaultConfig.CrossOriginOpenerPolicy
+	}
+	if cfg.CrossOriginResourcePolicy == "" {
+		cfg.CrossOriginResourcePolicy = DefaultConfig.CrossOriginResourcePolicy
+	}
+	if cfg.FrameOptions == "" {
+		cfg.FrameOptions = DefaultConfig.FrameOptions
+	}
+	if cfg.HSTSMaxAge == 0 {
+		cfg.HSTSMaxAge = DefaultConfig.HSTSMaxAge
+	}
+	if cfg.RedirectStatusCode == 0 {
+		cfg.RedirectStatusCode = DefaultConfig.RedirectStatusCode
+	}
+	if cfg.ReferrerPolicy == "" {
+		cfg.ReferrerPolicy = DefaultConfig.ReferrerPolicy
+	}
+
+	return cfg
+}
@@ -0,0 +1,271 @@
+package secure
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ghosind/dolphin"
+)
+
+// nonceKey is the key of the Content-Security-Policy nonce in the context state.
+const nonceKey = "github.com/ghosind/dolphin/middleware/secure.nonce"
+
+// Secure returns a middleware that sets the security headers to the responses, and redirects
+// the HTTP requests to HTTPS if it's enabled. The headers are set before the next handlers, so
+// they can be overridden by the handlers.
+func Secure(config ...Config) dolphin.HandlerFunc {
+	cfg := getConfig(config...)
+	proxies := parseTrustedProxies(cfg.TrustedProxies)
+	hsts := buildHSTS(&cfg)
+
+	csp := cfg.ContentSecurityPolicy
+	if csp != nil && !csp.Has("frame-ancestors") {
+		switch strings.ToUpper(cfg.FrameOptions) {
+		case "DENY":
+			csp = copyCSP(csp).Add("frame-ancestors", "'none'")
+		case "SAMEORIGIN":
+			csp = copyCSP(csp).Add("frame-ancestors", "'self'")
+		}
+	}
+	cspHeader := "Content-Security-Policy"
+	if cfg.ContentSecurityPolicyReportOnly {
+		cspHeader = "Content-Security-Policy-Report-Only"
+	}
+	staticPolicy := ""
+	if csp != nil && !csp.usesNonce() {
+		staticPolicy = csp.String()
+	}
+
+	return func(ctx *dolphin.Context) {
+		if cfg.Skip != nil && cfg.Skip(ctx) {
+			ctx.Next()
+			return
+		}
+
+		isHTTPS := isHTTPSRequest(ctx, proxies)
+
+		if cfg.HTTPSRedirect && !isHTTPS {
+			host := cfg.HTTPSHost
+			if host == "" {
+				host = trimPort(ctx.Request.Host())
+				if !isAllowedHost(host, cfg.AllowedHosts) {
+					ctx.String("Bad Request", http.StatusBadRequest)
+					ctx.Abort()
+					return
+				}
+			}
+			target := "https://" + joinPort(trimPort(host), cfg.HTTPSPort) + ctx.Path()
+			if query := ctx.RawQuery(); query != "" {
+				target += "?" + query
+			}
+
+			ctx.Redirect(target, cfg.RedirectStatusCode)
+			ctx.Abort()
+			return
+		}
+
+		setHeader(ctx, "X-Content-Type-Options", cfg.ContentTypeNosniff)
+		setHeader(ctx, "X-Frame-Options", cfg.FrameOptions)
+		setHeader(ctx, "Referrer-Policy", cfg.ReferrerPolicy)
+		setHeader(ctx, "Permissions-Policy", cfg.PermissionsPolicy)
+		setHeader(ctx, "Cross-Origin-Opener-Policy", cfg.CrossOriginOpenerPolicy)
+		setHeader(ctx, "Cross-Origin-Embedder-Policy", cfg.CrossOriginEmbedderPolicy)
+		setHeader(ctx, "Cross-Origin-Resource-Policy", cfg.CrossOriginResourcePolicy)
+		if isHTTPS {
+			setHeader(ctx, "Strict-Transport-Security", hsts)
+		}
+
+		if staticPolicy != "" {
+			ctx.SetHeader(cspHeader, staticPolicy)
+		} else if csp != nil {
+			nonce := newNonce()
+			ctx.Set(nonceKey, nonce)
+			ctx.SetHeader(cspHeader, csp.build(nonce))
+		}
+
+		ctx.Next()
+	}
+}
+
+// GetNonce returns the Content-Security-Policy nonce of the request, it's empty if the policy
+// has no nonce placeholder.
+//
+//	<script nonce="{{ .nonce }}">...</script>
+func GetNonce(ctx *dolphin.Context) string {
+	val, _ := ctx.Get(nonceKey)
+	nonce, _ := val.(string)
+
+	return nonce
+}
+
+// setHeader sets the header if the value is not empty or disabled.
+func setHeader(ctx *dolphin.Context, key, value string) {
+	if value != "" && value != Disabled {
+		ctx.SetHeader(key, value)
+	}
+}
+
+// buildHSTS returns the value of the Strict-Transport-Security header, or an empty string if
+// it's disabled.
+func buildHSTS(cfg *Config) string {
+	if cfg.HSTSMaxAge < 0 {
+		return ""
+	}
+
+	hsts := "max-age=" + strconv.FormatInt(int64(cfg.HSTSMaxAge/time.Second), 10)
+	if cfg.HSTSIncludeSubdomains {
+		hsts += "; includeSubDomains"
+	}
+	if cfg.HSTSPreload {
+		hsts += "; preload"
+	}
+
+	return hsts
+}
+
+// copyCSP returns a copy of the policy, so the policy in the config is not changed.
+func copyCSP(csp *CSP) *CSP {
+	cloned := NewCSP()
+	for _, d := range csp.directives {
+		cloned.Add(d.name, d.sources...)
+	}
+
+	return cloned
+}
+
+// newNonce returns a random nonce with 128 bits of entropy.
+func newNonce() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		panic(err)
+	}
+
+	return base64.StdEncoding.EncodeToString(buf)
+}
+
+// trimPort returns the host without the port and the brackets of the IPv6 address.
+func trimPort(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		return h
+	}
+
+	return strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+}
+
+// joinPort returns the host of the URL with the port, the port is omitted if it's the default
+// HTTPS port.
+func joinPort(host string, port int) string {
+	if port != 0 && port != 443 {
+		return net.JoinHostPort(host, strconv.Itoa(port))
+	}
+	if strings.Contains(host, ":") {
+		return "[" + host + "]"
+	}
+
+	return host
+}
+
+// isAllowedHost reports whether the host is in the allowed hosts, all hosts are allowed if the
+// list is empty.
+func isAllowedHost(host string, allowed []string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+
+	for _, h := range allowed {
+		if strings.EqualFold(host, h) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isHTTPSRequest reports whether the request is over HTTPS, the forwarded headers are only
+// used if the request is from a trusted proxy.
+func isHTTPSRequest(ctx *dolphin.Context, proxies []*net.IPNet) bool {
+	if ctx.IsTLS() {
+		return true
+	}
+	if !isTrustedProxy(ctx.IP(), proxies) {
+		return false
+	}
+
+	if proto := ctx.Header("X-Forwarded-Proto"); proto != "" {
+		proto, _, _ = strings.Cut(proto, ",")
+		return strings.EqualFold(strings.TrimSpace(proto), "https")
+	}
+	if strings.EqualFold(ctx.Header("X-Forwarded-Ssl"), "on") {
+		return true
+	}
+	if forwarded := ctx.Header("Forwarded"); forwarded != "" {
+		first, _, _ := strings.Cut(forwarded, ",")
+		for _, pair := range strings.Split(first, ";") {
+			key, value, _ := strings.Cut(strings.TrimSpace(pair), "=")
+			if strings.EqualFold(key, "proto") {
+				return strings.EqualFold(strings.Trim(value, `"`), "https")
+			}
+		}
+	}
+
+	return false
+}
+
+// isTrustedProxy reports whether the remote address is in the trusted proxies.
+func isTrustedProxy(addr string, proxies []*net.IPNet) bool {
+	if len(proxies) == 0 {
+		return false
+	}
+
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+
+	for _, proxy := range proxies {
+		if proxy.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseTrustedProxies parses the IP addresses and the CIDRs, it panics if any of them is
+// invalid.
+func parseTrustedProxies(proxies []string) []*net.IPNet {
+	nets := make([]*net.IPNet, 0, len(proxies))
+
+	for _, proxy := range proxies {
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				panic("secure: invalid trusted proxy " + proxy)
+			}
+
+			bits := 128
+			if ip.To4() != nil {
+				ip = ip.To4()
+				bits = 32
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, ipNet, err := net.ParseCIDR(proxy)
+		if err != nil {
+			panic("secure: invalid trusted proxy " + proxy)
+		}
+		nets = append(nets, ipNet)
+	}
+
+	return nets
+}
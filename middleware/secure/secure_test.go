@@ -0,0 +1,142 @@
+package secure
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ghosind/dolphin/internal/dolphintest"
+)
+
+func TestSecureDefaultHeaders(t *testing.T) {
+	rec, _ := dolphintest.Capture(httptest.NewRequest(http.MethodGet, "/", nil), Secure(), GetNonce)
+
+	expected := map[string]string{
+		"X-Content-Type-Options":       "nosniff",
+		"X-Frame-Options":              "DENY",
+		"Referrer-Policy":              "strict-origin-when-cross-origin",
+		"Cross-Origin-Opener-Policy":   "same-origin",
+		"Cross-Origin-Resource-Policy": "same-origin",
+		"Strict-Transport-Security":    "",
+		"Content-Security-Policy":      "",
+		"Cross-Origin-Embedder-Policy": "",
+	}
+	for key, value := range expected {
+		if actual := rec.Header().Get(key); actual != value {
+			t.Errorf("%s expect %q, actual %q", key, value, actual)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "https://example.com/", nil)
+	req.TLS = &tls.ConnectionState{}
+	rec, _ = dolphintest.Capture(req, Secure(Config{
+		FrameOptions:          Disabled,
+		HSTSIncludeSubdomains: true,
+		HSTSPreload:           true,
+	}), GetNonce)
+	if hsts := rec.Header().Get("Strict-Transport-Security"); hsts != "max-age=31536000; includeSubDomains; preload" {
+		t.Errorf("HSTS over TLS expect set, actual %q", hsts)
+	}
+	if fo := rec.Header().Get("X-Frame-Options"); fo != "" {
+		t.Errorf("Disabled X-Frame-Options expect not set, actual %q", fo)
+	}
+}
+
+func TestSecureCSPNonce(t *testing.T) {
+	csp := NewCSP().
+		Add("default-src", "'self'").
+		Add("script-src", "'self'", Nonce)
+	middleware := Secure(Config{ContentSecurityPolicy: csp})
+
+	rec, nonce := dolphintest.Capture(httptest.NewRequest(http.MethodGet, "/", nil), middleware, GetNonce)
+	if nonce == "" {
+		t.Fatalf("Nonce expect generated")
+	}
+	expected := "default-src 'self'; script-src 'self' 'nonce-" + nonce + "'; frame-ancestors 'none'"
+	if policy := rec.Header().Get("Content-Security-Policy"); policy != expected {
+		t.Errorf("CSP expect %q, actual %q", expected, policy)
+	}
+	if csp.Has("frame-ancestors") {
+		t.Errorf("CSP in config expect unchanged")
+	}
+
+	_, other := dolphintest.Capture(httptest.NewRequest(http.MethodGet, "/", nil), middleware, GetNonce)
+	if other == nonce {
+		t.Errorf("Nonce expect different in each request")
+	}
+
+	rec, nonce = dolphintest.Capture(httptest.NewRequest(http.MethodGet, "/", nil), Secure(Config{
+		ContentSecurityPolicy:           NewCSP().Add("default-src", "'self'").Add("frame-ancestors", "https://example.com"),
+		ContentSecurityPolicyReportOnly: true,
+	}), GetNonce)
+	if policy := rec.Header().Get("Content-Security-Policy-Report-Only"); policy != "default-src 'self'; frame-ancestors https://example.com" || nonce != "" {
+		t.Errorf("Report-only CSP expect static policy, actual %q %q", policy, nonce)
+	}
+}
+
+func TestSecureHTTPSRedirectHost(t *testing.T) {
+	cases := []struct {
+		config   Config
+		url      string
+		code     int
+		location string
+	}{
+		{Config{}, "http://example.com:8080/x", http.StatusMovedPermanently, "https://example.com/x"},
+		{Config{HTTPSPort: 8443}, "http://example.com:8080/x", http.StatusMovedPermanently, "https://example.com:8443/x"},
+		{Config{HTTPSPort: 8443}, "http://[::1]:8080/x", http.StatusMovedPermanently, "https://[::1]:8443/x"},
+		{Config{}, "http://[::1]:8080/x", http.StatusMovedPermanently, "https://[::1]/x"},
+		{Config{HTTPSHost: "example.com"}, "http://evil.com/x", http.StatusMovedPermanently, "https://example.com/x"},
+		{Config{AllowedHosts: []string{"example.com"}}, "http://EXAMPLE.com:8080/x", http.StatusMovedPermanently, "https://EXAMPLE.com/x"},
+		{Config{AllowedHosts: []string{"example.com"}}, "http://evil.com/x", http.StatusBadRequest, ""},
+	}
+
+	for _, c := range cases {
+		c.config.HTTPSRedirect = true
+
+		rec, _ := dolphintest.Capture(httptest.NewRequest(http.MethodGet, c.url, nil), Secure(c.config), GetNonce)
+		if rec.Code != c.code || rec.Header().Get("Location") != c.location {
+			t.Errorf("%s expect %d %q, actual %d %q", c.url, c.code, c.location, rec.Code, rec.Header().Get("Location"))
+		}
+	}
+}
+
+func TestSecureHTTPSRedirect(t *testing.T) {
+	middleware := Secure(Config{
+		HTTPSRedirect:  true,
+		TrustedProxies: []string{"10.0.0.0/8"},
+	})
+
+	rec, _ := dolphintest.Capture(httptest.NewRequest(http.MethodGet, "http://example.com/path?q=1", nil), middleware, GetNonce)
+	if rec.Code != http.StatusMovedPermanently || rec.Header().Get("Location") != "https://example.com/path?q=1" {
+		t.Errorf("HTTP request expect redirect, actual %d %q", rec.Code, rec.Header().Get("Location"))
+	}
+
+	cases := []struct {
+		remoteAddr string
+		header     string
+		value      string
+		redirect   bool
+	}{
+		{"10.0.0.1:1234", "X-Forwarded-Proto", "https", false},
+		{"10.0.0.1:1234", "X-Forwarded-Ssl", "on", false},
+		{"10.0.0.1:1234", "Forwarded", `for=1.2.3.4;proto="https"`, false},
+		{"10.0.0.1:1234", "X-Forwarded-Proto", "http", true},
+		{"192.168.0.1:1234", "X-Forwarded-Proto", "https", true},
+	}
+
+	for _, c := range cases {
+		req := httptest.NewRequest(http.MethodGet, "http://example.com/", nil)
+		req.RemoteAddr = c.remoteAddr
+		req.Header.Set(c.header, c.value)
+
+		rec, _ := dolphintest.Capture(req, middleware, GetNonce)
+		if redirect := rec.Code == http.StatusMovedPermanently; redirect != c.redirect {
+			t.Errorf("%s with %s: %s expect redirect %v, actual %d", c.remoteAddr, c.header, c.value, c.redirect, rec.Code)
+		}
+		if !c.redirect && !strings.HasPrefix(rec.Header().Get("Strict-Transport-Security"), "max-age=") {
+			t.Errorf("%s with %s: %s expect HSTS, actual %q", c.remoteAddr, c.header, c.value, rec.Header().Get("Strict-Transport-Security"))
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package session
+
+import (
+	"net/http"
+	"time"
+)
+
+// Config is the session middleware config.
+type Config struct {
+	// Domain is the domain of the session cookie.
+	Domain string
+	// MaxAge is the lifetime of the sessions, default 24 hours. The lifetime is refreshed when
+	// the session is saved.
+	MaxAge time.Duration
+	// Name is the name of the session cookie, default "dolphin_session".
+	Name string
+	// Path is the path of the session cookie, default "/".
+	Path string
+	// SameSite is the SameSite attribute of the session cookie, default http.SameSiteLaxMode.
+	SameSite http.SameSite
+	// Secure indicates the session cookie is only sent over HTTPS, it's always set for the
+	// requests over TLS.
+	Secure bool
+	// Store is the storage of the sessions, default a new in-memory store.
+	Store Store
+}
+
+// DefaultConfig is the default session middleware config.
+var DefaultConfig = Config{
+	MaxAge:   24 * time.Hour,
+	Name:     "dolphin_session",
+	Path:     "/",
+	SameSite: http.SameSiteLaxMode,
+}
+
+func getConfig(config ...Config) Config {
+	cfg := DefaultConfig
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+
+	if cfg.MaxAge <= 0 {
+		cfg.MaxAge = DefaultConfig.MaxAge
+	}
+	if cfg.Name == "" {
+		cfg.Name = DefaultConfig.Name
+	}
+	if cfg.Path == "" {
+		cfg.Path = DefaultConfig.Path
+	}
+	if cfg.SameSite == 0 {
+		cfg.SameSite = DefaultConfig.SameSite
+	}
+	if cfg.Store == nil {
+		cfg.Store = NewMemoryStore()
+	}
+
+	return cfg
+}
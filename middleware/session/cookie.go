@@ -0,0 +1,106 @@
+package session
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/gob"
+	"time"
+
+	"github.com/ghosind/dolphin"
+	"github.com/ghosind/dolphin/internal/aead"
+)
+
+// maxCookieSize is the maximum size of the encoded session cookie value, most browsers limit
+// the size of a cookie to 4096 bytes including the name and the attributes.
+const maxCookieSize = 3800
+
+// cookiePayload is the encoded session in the cookie.
+type cookiePayload struct {
+	Expires int64
+	ID      string
+	Values  map[string]any
+}
+
+// CookieStore is the session store that keeps the sessions in the cookies, the sessions are
+// encoded by encoding/gob and encrypted by AES-GCM, so they can't be read or tampered by the
+// clients.
+type CookieStore struct {
+	cipher *aead.Cipher
+	now    func() time.Time
+}
+
+// NewCookieStore creates a cookie store with the AES keys, the keys should be 16, 24, or 32
+// bytes. The first key is used to encrypt the sessions, and all keys are used to decrypt the
+// sessions, so the keys can be rotated by adding a new key to the head of the list and removing
+// the old keys after the sessions encrypted by them are expired.
+func NewCookieStore(keys ...[]byte) (*CookieStore, error) {
+	if len(keys) == 0 {
+		return nil, ErrNoKey
+	}
+
+	c, err := aead.New(keys...)
+	if err != nil {
+		return nil, ErrInvalidKey
+	}
+
+	store := &CookieStore{
+		cipher: c,
+		now:    time.Now,
+	}
+
+	return store, nil
+}
+
+// Load decrypts and decodes the session from the cookie value.
+func (store *CookieStore) Load(value string) (*dolphin.Session, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, nil
+	}
+
+	plaintext, ok := store.cipher.Open(data, nil)
+	if !ok {
+		return nil, nil
+	}
+
+	var payload cookiePayload
+	if err := gob.NewDecoder(bytes.NewReader(plaintext)).Decode(&payload); err != nil {
+		return nil, nil
+	}
+	if store.now().Unix() >= payload.Expires || payload.ID == "" {
+		return nil, nil
+	}
+
+	return dolphin.NewSession(payload.ID, payload.Values), nil
+}
+
+// Save encodes and encrypts the session into the cookie value.
+func (store *CookieStore) Save(session *dolphin.Session, maxAge time.Duration) (string, error) {
+	payload := cookiePayload{
+		Expires: store.now().Add(maxAge).Unix(),
+		ID:      session.ID(),
+		Values:  session.Values(),
+	}
+
+	buf := new(bytes.Buffer)
+	if err := gob.NewEncoder(buf).Encode(&payload); err != nil {
+		return "", err
+	}
+
+	sealed, err := store.cipher.Seal(buf.Bytes(), nil)
+	if err != nil {
+		return "", err
+	}
+
+	value := base64.RawURLEncoding.EncodeToString(sealed)
+	if len(value) > maxCookieSize {
+		return "", ErrCookieTooLarge
+	}
+
+	return value, nil
+}
+
+// Delete does nothing, the session cookie is expired by the middleware.
+func (store *CookieStore) Delete(id string) error {
+	return nil
+}
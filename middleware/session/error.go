@@ -0,0 +1,13 @@
+package session
+
+import "errors"
+
+// ErrInvalidKey is returned by NewCookieStore when the key is not a valid AES key.
+var ErrInvalidKey = errors.New("invalid key size, it should be 16, 24, or 32 bytes")
+
+// ErrNoKey is returned by NewCookieStore when no key is provided.
+var ErrNoKey = errors.New("no key")
+
+// ErrCookieTooLarge is returned by the cookie store when the encoded session exceeds the size
+// limit of the cookies.
+var ErrCookieTooLarge = errors.New("session cookie too large")
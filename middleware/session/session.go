@@ -0,0 +1,94 @@
+package session
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/ghosind/dolphin"
+)
+
+// Session returns a middleware that provides the session by ctx.Session(). The session is
+// loaded from the store when it's first accessed, and it's saved in the response phase only if
+// it's modified.
+func Session(config ...Config) dolphin.HandlerFunc {
+	cfg := getConfig(config...)
+
+	loader := func(ctx *dolphin.Context) *dolphin.Session {
+		return load(ctx, &cfg)
+	}
+
+	return func(ctx *dolphin.Context) {
+		ctx.SetSessionLoader(loader)
+
+		ctx.Next()
+
+		if session := ctx.LoadedSession(); session != nil {
+			save(ctx, &cfg, session)
+		}
+	}
+}
+
+// load loads the session by the session cookie of the request, or creates a new session if the
+// cookie is not set or the session is not found.
+func load(ctx *dolphin.Context, cfg *Config) *dolphin.Session {
+	if cookie, err := ctx.Cookie(cfg.Name); err == nil && cookie.Value != "" {
+		session, err := cfg.Store.Load(cookie.Value)
+		if err != nil {
+			ctx.Log("Failed to load session: %v\n", err)
+		} else if session != nil {
+			return session
+		}
+	}
+
+	return dolphin.NewSession("", nil)
+}
+
+// save persists the session if it's modified, and sets the session cookie to the response.
+func save(ctx *dolphin.Context, cfg *Config, session *dolphin.Session) {
+	if previousID := session.PreviousID(); previousID != "" {
+		if err := cfg.Store.Delete(previousID); err != nil {
+			ctx.Log("Failed to delete session: %v\n", err)
+		}
+	}
+
+	if session.IsDestroyed() {
+		if err := cfg.Store.Delete(session.ID()); err != nil {
+			ctx.Log("Failed to delete session: %v\n", err)
+		}
+		if !session.IsNew() {
+			setCookie(ctx, cfg, "", -1)
+		}
+		return
+	}
+
+	if !session.IsModified() || session.IsNew() && len(session.Values()) == 0 {
+		return
+	}
+
+	value, err := cfg.Store.Save(session, cfg.MaxAge)
+	if err != nil {
+		ctx.Log("Failed to save session: %v\n", err)
+		return
+	}
+
+	setCookie(ctx, cfg, value, int(cfg.MaxAge/time.Second))
+}
+
+// setCookie sets the session cookie to the response.
+func setCookie(ctx *dolphin.Context, cfg *Config, value string, maxAge int) {
+	cookie := &http.Cookie{
+		Name:     cfg.Name,
+		Value:    value,
+		Path:     cfg.Path,
+		Domain:   cfg.Domain,
+		MaxAge:   maxAge,
+		Secure:   cfg.Secure || ctx.IsTLS(),
+		HttpOnly: true,
+		SameSite: cfg.SameSite,
+	}
+	if maxAge > 0 {
+		cookie.Expires = time.Now().Add(time.Duration(maxAge) * time.Second)
+	}
+
+	ctx.AddCookies(cookie)
+}
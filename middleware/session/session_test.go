@@ -0,0 +1,145 @@
+package session
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ghosind/dolphin"
+)
+
+// testSessionApp creates an app that uses the session middleware with the store.
+func testSessionApp(store Store) *dolphin.App {
+	app := dolphin.New(nil)
+	app.Use(Session(Config{Store: store}), func(ctx *dolphin.Context) {
+		session := ctx.Session()
+
+		switch ctx.Path() {
+		case "/login":
+			session.Regenerate()
+			session.Set("user", "dolphin")
+			session.AddFlash("Welcome")
+		case "/logout":
+			session.Destroy()
+		case "/flash":
+			ctx.String(strings.Join(session.Flashes(), ","))
+			return
+		}
+
+		ctx.String(session.GetString("user"))
+	})
+
+	return app
+}
+
+// testSessionRequest makes a request with the cookie, and returns the response and the new
+// session cookie.
+func testSessionRequest(app *dolphin.App, path string, cookie *http.Cookie) (*httptest.ResponseRecorder, *http.Cookie) {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, req)
+
+	for _, c := range rec.Result().Cookies() {
+		if c.Name == DefaultConfig.Name {
+			return rec, c
+		}
+	}
+
+	return rec, nil
+}
+
+func testSessionFlow(t *testing.T, store Store) {
+	app := testSessionApp(store)
+
+	_, cookie := testSessionRequest(app, "/", nil)
+	if cookie != nil {
+		t.Errorf("Unmodified session expect no cookie, actual %v", cookie)
+	}
+
+	_, cookie = testSessionRequest(app, "/login", nil)
+	if cookie == nil || !cookie.HttpOnly || cookie.SameSite != http.SameSiteLaxMode {
+		t.Fatalf("Login expect HttpOnly and SameSite=Lax session cookie, actual %v", cookie)
+	}
+
+	rec, newCookie := testSessionRequest(app, "/", cookie)
+	if rec.Body.String() != "dolphin" || newCookie != nil {
+		t.Errorf("Session expect loaded without saving, actual %q %v", rec.Body.String(), newCookie)
+	}
+
+	rec, newCookie = testSessionRequest(app, "/flash", cookie)
+	if rec.Body.String() != "Welcome" || newCookie == nil {
+		t.Errorf("Flash expect read and session saved, actual %q %v", rec.Body.String(), newCookie)
+	}
+	cookie = newCookie
+
+	rec, _ = testSessionRequest(app, "/flash", cookie)
+	if rec.Body.String() != "" {
+		t.Errorf("Flash expect removed after read, actual %q", rec.Body.String())
+	}
+
+	rec, newCookie = testSessionRequest(app, "/logout", cookie)
+	if newCookie == nil || newCookie.MaxAge >= 0 {
+		t.Errorf("Logout expect expired cookie, actual %v", newCookie)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	store := NewMemoryStore()
+	testSessionFlow(t, store)
+
+	app := testSessionApp(store)
+	_, first := testSessionRequest(app, "/login", nil)
+	_, second := testSessionRequest(app, "/login", first)
+	if second == nil || second.Value == first.Value {
+		t.Fatalf("Regenerate expect new session ID, actual %v", second)
+	}
+	if rec, _ := testSessionRequest(app, "/", first); rec.Body.String() != "" {
+		t.Errorf("Previous session expect deleted, actual %q", rec.Body.String())
+	}
+
+	now := time.Now()
+	store.now = func() time.Time { return now.Add(25 * time.Hour) }
+	if rec, _ := testSessionRequest(app, "/", second); rec.Body.String() != "" {
+		t.Errorf("Expired session expect not loaded, actual %q", rec.Body.String())
+	}
+}
+
+func TestCookieStore(t *testing.T) {
+	oldKey := bytes.Repeat([]byte{1}, 32)
+	newKey := bytes.Repeat([]byte{2}, 32)
+
+	store, err := NewCookieStore(oldKey)
+	if err != nil {
+		t.Fatalf("NewCookieStore expect no error, actual %v", err)
+	}
+	testSessionFlow(t, store)
+
+	_, cookie := testSessionRequest(testSessionApp(store), "/login", nil)
+
+	// Key rotation
+	rotated, _ := NewCookieStore(newKey, oldKey)
+	if rec, _ := testSessionRequest(testSessionApp(rotated), "/", cookie); rec.Body.String() != "dolphin" {
+		t.Errorf("Rotated store expect decrypt with old key, actual %q", rec.Body.String())
+	}
+
+	removed, _ := NewCookieStore(newKey)
+	if rec, _ := testSessionRequest(testSessionApp(removed), "/", cookie); rec.Body.String() != "" {
+		t.Errorf("Removed key expect session invalid, actual %q", rec.Body.String())
+	}
+
+	tampered := &http.Cookie{Name: cookie.Name, Value: cookie.Value[:len(cookie.Value)-2] + "AA"}
+	if rec, _ := testSessionRequest(testSessionApp(store), "/", tampered); rec.Body.String() != "" {
+		t.Errorf("Tampered cookie expect session invalid, actual %q", rec.Body.String())
+	}
+
+	if _, err := NewCookieStore([]byte("short")); err != ErrInvalidKey {
+		t.Errorf("Short key expect ErrInvalidKey, actual %v", err)
+	}
+}
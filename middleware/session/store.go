@@ -0,0 +1,103 @@
+package session
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ghosind/dolphin"
+)
+
+// Store is the storage of the sessions. The server-side stores like the in-memory store use the
+// session ID as the cookie value, and the cookie store encodes the whole session into the cookie
+// value.
+type Store interface {
+	// Load returns the session of the cookie value. It returns nil without error if the session
+	// is not found, expired, or invalid.
+	Load(value string) (*dolphin.Session, error)
+	// Save persists the session with the lifetime, and returns the value of the session cookie.
+	Save(session *dolphin.Session, maxAge time.Duration) (string, error)
+	// Delete deletes the session by the ID.
+	Delete(id string) error
+}
+
+// memoryEntry is a session in the in-memory store.
+type memoryEntry struct {
+	expires time.Time
+	values  map[string]any
+}
+
+// MemoryStore is the in-memory session store, the sessions are lost when the process exits and
+// not shared across instances.
+type MemoryStore struct {
+	entries   map[string]*memoryEntry
+	lastSweep time.Time
+	mu        sync.Mutex
+	now       func() time.Time
+}
+
+// sweepInterval is the interval of removing the expired sessions from the in-memory store.
+const sweepInterval = time.Minute
+
+// NewMemoryStore creates a new in-memory session store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		entries: make(map[string]*memoryEntry),
+		now:     time.Now,
+	}
+}
+
+// Load returns the session of the ID.
+func (store *MemoryStore) Load(id string) (*dolphin.Session, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	entry, ok := store.entries[id]
+	if !ok {
+		return nil, nil
+	}
+	if !store.now().Before(entry.expires) {
+		delete(store.entries, id)
+		return nil, nil
+	}
+
+	values := make(map[string]any, len(entry.values))
+	for k, v := range entry.values {
+		values[k] = v
+	}
+
+	return dolphin.NewSession(id, values), nil
+}
+
+// Save saves the session, and returns the session ID as the cookie value.
+func (store *MemoryStore) Save(session *dolphin.Session, maxAge time.Duration) (string, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	now := store.now()
+	if now.Sub(store.lastSweep) > sweepInterval {
+		for id, entry := range store.entries {
+			if !now.Before(entry.expires) {
+				delete(store.entries, id)
+			}
+		}
+		store.lastSweep = now
+	}
+
+	id := session.ID()
+	store.entries[id] = &memoryEntry{
+		expires: now.Add(maxAge),
+		values:  session.Values(),
+	}
+
+	return id, nil
+}
+
+// Delete deletes the session of the ID.
+func (store *MemoryStore) Delete(id string) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	delete(store.entries, id)
+
+	return nil
+}
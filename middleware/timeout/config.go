@@ -0,0 +1,38 @@
+package timeout
+
+import (
+	"time"
+
+	"github.com/ghosind/dolphin"
+)
+
+// Config is the timeout middleware config.
+type Config struct {
+	// Handler is the handler that will be triggered when the request is timed out. It'll
+	// return a 503 (Service Unavailable) response if the handler is not set.
+	Handler dolphin.HandlerFunc
+	// Timeout is the maximum duration of the following handlers, default 30 seconds.
+	Timeout time.Duration
+}
+
+// DefaultConfig is the default timeout middleware config.
+var DefaultConfig = Config{
+	Handler: defaultHandler,
+	Timeout: 30 * time.Second,
+}
+
+func getConfig(config ...Config) Config {
+	if len(config) < 1 {
+		return DefaultConfig
+	}
+
+	cfg := config[0]
+	if cfg.Handler == nil {
+		cfg.Handler = DefaultConfig.Handler
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultConfig.Timeout
+	}
+
+	return cfg
+}
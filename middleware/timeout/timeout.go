@@ -0,0 +1,82 @@
+package timeout
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/ghosind/dolphin"
+)
+
+// Timeout returns a middleware that limits the execution time of the following handlers. The
+// request context is canceled when the deadline passes, so the
+// handlers can stop their work, and the timeout handler responds to the client.
+//
+// The following handlers run with a copy of the context in another goroutine. The copy is
+// adopted if the handlers finish in time, including the response, the state, the errors, the
+// matched route pattern and the path variables, otherwise it's discarded, so the late handlers
+// can never change the response that has been written. The copy has its own request with the
+// deadline context, and the streaming body that the late handlers set to the discarded copy is
+// closed after they return.
+func Timeout(config ...Config) dolphin.HandlerFunc {
+	cfg := getConfig(config...)
+
+	return func(ctx *dolphin.Context) {
+		c, cancel := context.WithTimeout(ctx.Request.Context(), cfg.Timeout)
+		defer cancel()
+
+		copied := ctx.Copy()
+		// The late handlers may still use the request after the response has been written, so the
+		// copy gets its own request instead of sharing the one that owned by the server.
+		copied.Request.SetContext(c)
+		stream := copied.Response.Stream()
+		done := make(chan struct{})
+		panicChan := make(chan any, 1)
+
+		go func() {
+			defer func() {
+				if p := recover(); p != nil {
+					panicChan <- p
+					return
+				}
+				close(done)
+			}()
+
+			copied.Next()
+		}()
+
+		select {
+		case <-done:
+			// The rest handlers have been executed with the copy.
+			ctx.Abort()
+			ctx.Adopt(copied)
+		case p := <-panicChan:
+			ctx.Abort()
+			panic(p)
+		case <-c.Done():
+			ctx.Abort()
+			cfg.Handler(ctx)
+
+			go discard(copied, stream, done, panicChan)
+		}
+	}
+}
+
+// discard waits for the late handlers of the discarded copy to return, and closes the streaming
+// body that they set, as the copy never goes through the response writing.
+func discard(copied *dolphin.Context, stream io.Reader, done <-chan struct{}, panicChan <-chan any) {
+	select {
+	case <-done:
+	case <-panicChan:
+	}
+
+	// The streaming body that set before the copy is shared with the context, and it's released by
+	// the context.
+	if copied.Response.Stream() != stream {
+		copied.Response.ResetBody()
+	}
+}
+
+func defaultHandler(ctx *dolphin.Context) {
+	ctx.String("Service Unavailable", http.StatusServiceUnavailable)
+}
@@ -0,0 +1,130 @@
+package timeout
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ghosind/dolphin"
+)
+
+func TestTimeout(t *testing.T) {
+	release := make(chan struct{})
+	lateDone := make(chan struct{})
+
+	app := dolphin.New(nil)
+	app.Use(Timeout(Config{Timeout: 50 * time.Millisecond}), func(ctx *dolphin.Context) {
+		ctx.Set("handled", true)
+
+		if ctx.Path() == "/slow" {
+			<-ctx.Done()
+			<-release
+			// The late handler writes to the discarded copy.
+			ctx.String("late", http.StatusOK)
+			close(lateDone)
+			return
+		}
+
+		ctx.String("fast", http.StatusCreated)
+	})
+
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/fast", nil))
+	if rec.Code != http.StatusCreated || rec.Body.String() != "fast" {
+		t.Errorf("Fast request expect 201 \"fast\", actual %d %q", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/slow", nil))
+	close(release)
+	<-lateDone
+
+	if rec.Code != http.StatusServiceUnavailable || rec.Body.String() != "Service Unavailable" {
+		t.Errorf("Slow request expect 503, actual %d %q", rec.Code, rec.Body.String())
+	}
+}
+
+// testStream is the streaming body that reports when it's closed.
+type testStream struct {
+	io.Reader
+	closed chan struct{}
+}
+
+func (s *testStream) Close() error {
+	close(s.closed)
+	return nil
+}
+
+func TestTimeoutLateStream(t *testing.T) {
+	stream := &testStream{Reader: strings.NewReader("late"), closed: make(chan struct{})}
+	release := make(chan struct{})
+
+	app := dolphin.New(nil)
+	app.Use(Timeout(Config{Timeout: 50 * time.Millisecond}), func(ctx *dolphin.Context) {
+		<-ctx.Done()
+		<-release
+		ctx.Stream("text/plain", stream)
+	})
+
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	close(release)
+
+	select {
+	case <-stream.closed:
+	case <-time.After(time.Second):
+		t.Errorf("Late stream expect closed, actual not closed")
+	}
+	if rec.Code != http.StatusServiceUnavailable || rec.Body.String() != "Service Unavailable" {
+		t.Errorf("Slow request expect 503, actual %d %q", rec.Code, rec.Body.String())
+	}
+}
+
+func TestTimeoutRoute(t *testing.T) {
+	router := dolphin.NewRouter()
+	router.GET("/users/:id", func(ctx *dolphin.Context) {
+		ctx.String(ctx.PathVariable("id"))
+	})
+
+	var pattern, id string
+	app := dolphin.New(nil)
+	app.Use(func(ctx *dolphin.Context) {
+		ctx.Next()
+		pattern, id = ctx.RoutePattern(), ctx.PathVariable("id")
+	}, Timeout(), router.Routes())
+
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/1", nil))
+	if rec.Body.String() != "1" {
+		t.Errorf("Response body expect \"1\", actual %q", rec.Body.String())
+	}
+	if pattern != "/users/:id" {
+		t.Errorf("Route pattern expect \"/users/:id\", actual %q", pattern)
+	}
+	if id != "1" {
+		t.Errorf("Path variable expect \"1\", actual %q", id)
+	}
+}
+
+func TestTimeoutPanic(t *testing.T) {
+	app := dolphin.New(nil)
+	app.Use(func(ctx *dolphin.Context) {
+		defer func() {
+			if p := recover(); p != nil {
+				ctx.String("recovered", http.StatusInternalServerError)
+			}
+		}()
+		ctx.Next()
+	}, Timeout(), func(ctx *dolphin.Context) {
+		panic("boom")
+	})
+
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusInternalServerError || rec.Body.String() != "recovered" {
+		t.Errorf("Panic expect propagated to the outer middleware, actual %d %q", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,204 @@
+package dolphin
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// ProblemContentType is the media type of the problem details documents.
+const ProblemContentType = "application/problem+json"
+
+// Problem is the problem details of an HTTP API error that defined by RFC 9457. It can be
+// written by Context.Problem, or returned as an error to be handled by Context.Error.
+//
+//	return ctx.Problem(dolphin.NewProblem(http.StatusForbidden, "Your balance is 30, but that costs 50.").
+//		WithType("https://example.com/probs/out-of-credit").
+//		With("balance", 30))
+type Problem struct {
+	// Detail is the human-readable explanation specific to this occurrence of the problem.
+	Detail string
+	// Extensions is the additional members of the problem, they're serialized as the top-level
+	// members of the document.
+	Extensions map[string]any
+	// Instance is the URI reference that identifies the specific occurrence of the problem.
+	Instance string
+	// Status is the HTTP status code of the problem.
+	Status int
+	// Title is the short, human-readable summary of the problem type, default the status text.
+	Title string
+	// Type is the URI reference that identifies the problem type, it's "about:blank" if it's not
+	// set.
+	Type string
+}
+
+// problemMembers is the standard members of the problem details.
+var problemMembers = []string{"detail", "instance", "status", "title", "type"}
+
+// NewProblem creates a problem with the status code and the optional detail, the title is the
+// status text.
+func NewProblem(status int, detail ...string) *Problem {
+	problem := &Problem{
+		Status: status,
+		Title:  http.StatusText(status),
+	}
+	if len(detail) > 0 {
+		problem.Detail = detail[0]
+	}
+
+	return problem
+}
+
+// Error returns the title and the detail of the problem.
+func (problem *Problem) Error() string {
+	if problem.Detail == "" {
+		return problem.Title
+	}
+
+	return problem.Title + ": " + problem.Detail
+}
+
+// With sets an extension member of the problem, and returns the problem itself. The standard
+// members can't be overwritten by the extensions.
+func (problem *Problem) With(key string, value any) *Problem {
+	if problem.Extensions == nil {
+		problem.Extensions = make(map[string]any)
+	}
+	problem.Extensions[key] = value
+
+	return problem
+}
+
+// WithInstance sets the instance of the problem, and returns the problem itself.
+func (problem *Problem) WithInstance(instance string) *Problem {
+	problem.Instance = instance
+	return problem
+}
+
+// WithType sets the type of the problem, and returns the problem itself.
+func (problem *Problem) WithType(typ string) *Problem {
+	problem.Type = typ
+	return problem
+}
+
+// MarshalJSON marshals the problem to a JSON object, the extensions are the top-level members.
+func (problem Problem) MarshalJSON() ([]byte, error) {
+	members := make(map[string]any, len(problem.Extensions)+len(problemMembers))
+	for k, v := range problem.Extensions {
+		members[k] = v
+	}
+	for _, key := range problemMembers {
+		delete(members, key)
+	}
+
+	if problem.Detail != "" {
+		members["detail"] = problem.Detail
+	}
+	if problem.Instance != "" {
+		members["instance"] = problem.Instance
+	}
+	if problem.Status != 0 {
+		members["status"] = problem.Status
+	}
+	if problem.Title != "" {
+		members["title"] = problem.Title
+	}
+	typ := problem.Type
+	if typ == "" {
+		typ = "about:blank"
+	}
+	members["type"] = typ
+
+	return json.Marshal(members)
+}
+
+// UnmarshalJSON unmarshals the problem from a JSON object, the unknown members are stored in the
+// extensions.
+func (problem *Problem) UnmarshalJSON(data []byte) error {
+	var standard struct {
+		Detail   string `json:"detail"`
+		Instance string `json:"instance"`
+		Status   int    `json:"status"`
+		Title    string `json:"title"`
+		Type     string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &standard); err != nil {
+		return err
+	}
+
+	var members map[string]any
+	if err := json.Unmarshal(data, &members); err != nil {
+		return err
+	}
+	for _, key := range problemMembers {
+		delete(members, key)
+	}
+	if len(members) == 0 {
+		members = nil
+	}
+
+	*problem = Problem{
+		Detail:     standard.Detail,
+		Extensions: members,
+		Instance:   standard.Instance,
+		Status:     standard.Status,
+		Title:      standard.Title,
+		Type:       standard.Type,
+	}
+
+	return nil
+}
+
+// Problem writes the problem details to the response body with the content type
+// "application/problem+json", and sets the status code to the problem status. The status code is
+// 500 (Internal Server Error) if the problem status is not set.
+func (ctx *Context) Problem(problem *Problem) error {
+	status := problem.Status
+	if status == 0 {
+		status = http.StatusInternalServerError
+	}
+
+	payload, err := json.Marshal(problem)
+	if err != nil {
+		return err
+	}
+
+	return ctx.send(payload, ProblemContentType, status)
+}
+
+// ProblemDetailsEnabled reports whether the app responds the errors as problem details by
+// Config.ProblemDetails. It's used by the middleware to choose the error response format.
+func (ctx *Context) ProblemDetailsEnabled() bool {
+	return ctx.app.problemDetails
+}
+
+// errorToProblem converts the error to the problem details. The messages of the errors other
+// than Problem, HTTPError, and BindingError are not exposed.
+func errorToProblem(err error) *Problem {
+	var problem *Problem
+	if errors.As(err, &problem) {
+		return problem
+	}
+
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		problem = NewProblem(errorStatusCode(httpErr.StatusCode))
+		if httpErr.Message != problem.Title && httpErr.Message != http.StatusText(httpErr.StatusCode) {
+			problem.Detail = httpErr.Message
+		}
+		if httpErr.Code != "" {
+			problem.With("code", httpErr.Code)
+		}
+		if httpErr.Details != nil {
+			problem.With("details", httpErr.Details)
+		}
+		return problem
+	}
+
+	var bindingErr *BindingError
+	if errors.As(err, &bindingErr) {
+		return NewProblem(http.StatusBadRequest, bindingErr.Error())
+	}
+
+	return NewProblem(http.StatusInternalServerError)
+}
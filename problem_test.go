@@ -0,0 +1,115 @@
+package dolphin
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestProblemJSON(t *testing.T) {
+	problem := NewProblem(http.StatusForbidden, "Your balance is 30, but that costs 50.").
+		WithType("https://example.com/probs/out-of-credit").
+		WithInstance("/account/12345/msgs/abc").
+		With("balance", 30).
+		With("status", 200)
+
+	data, err := json.Marshal(problem)
+	if err != nil {
+		t.Fatalf("Marshal expect no error, actual %v", err)
+	}
+	expected := `{"balance":30,"detail":"Your balance is 30, but that costs 50.","instance":"/account/12345/msgs/abc",` +
+		`"status":403,"title":"Forbidden","type":"https://example.com/probs/out-of-credit"}`
+	if string(data) != expected {
+		t.Errorf("Marshal expect %s, actual %s", expected, data)
+	}
+
+	var decoded Problem
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal expect no error, actual %v", err)
+	}
+	problem.Extensions = map[string]any{"balance": float64(30)}
+	if !reflect.DeepEqual(&decoded, problem) {
+		t.Errorf("Unmarshal expect %+v, actual %+v", problem, decoded)
+	}
+}
+
+func TestContextProblem(t *testing.T) {
+	rec := testRequest(func(ctx *Context) {
+		ctx.Problem(NewProblem(http.StatusConflict))
+	}, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if rec.Code != http.StatusConflict {
+		t.Errorf("status code expect %d, actual %d", http.StatusConflict, rec.Code)
+	}
+	if contentType := rec.Header().Get("Content-Type"); contentType != ProblemContentType {
+		t.Errorf("content type expect %q, actual %q", ProblemContentType, contentType)
+	}
+	if body := rec.Body.String(); body != `{"status":409,"title":"Conflict","type":"about:blank"}` {
+		t.Errorf("body expect problem details, actual %q", body)
+	}
+}
+
+func TestProblemDetailsEnabled(t *testing.T) {
+	router := NewRouter(RouterConfig{MethodNotAllowedHandler: DefaultMethodNotAllowedHandler})
+	router.POST("/users", WithError(func(ctx *Context) error {
+		var payload struct {
+			Name string `json:"name"`
+		}
+		if err := ctx.PostJSON(&payload); err != nil {
+			return err
+		}
+		return NewHTTPError(http.StatusConflict, "user exists").WithCode("user_exists")
+	}))
+	router.GET("/internal", WithError(func(ctx *Context) error {
+		return errors.New("database is down")
+	}))
+	router.GET("/redirect", WithError(func(ctx *Context) error {
+		return NewHTTPError(http.StatusFound)
+	}))
+
+	app := New(&Config{ProblemDetails: true})
+	app.Use(router.Routes())
+
+	cases := []struct {
+		method  string
+		path    string
+		body    string
+		code    int
+		detail  string
+		errCode any
+	}{
+		{http.MethodGet, "/unknown", "", http.StatusNotFound, "", nil},
+		{http.MethodGet, "/users", "", http.StatusMethodNotAllowed, "", nil},
+		{http.MethodPost, "/users", "{", http.StatusBadRequest, "unexpected end of JSON input", nil},
+		{http.MethodPost, "/users", `{"name":"dolphin"}`, http.StatusConflict, "user exists", "user_exists"},
+		{http.MethodGet, "/internal", "", http.StatusInternalServerError, "", nil},
+		{http.MethodGet, "/redirect", "", http.StatusInternalServerError, "", nil},
+	}
+
+	for _, c := range cases {
+		rec := testServe(app, httptest.NewRequest(c.method, c.path, strings.NewReader(c.body)))
+
+		if rec.Code != c.code {
+			t.Errorf("%s %s status code expect %d, actual %d", c.method, c.path, c.code, rec.Code)
+		}
+		if contentType := rec.Header().Get("Content-Type"); contentType != ProblemContentType {
+			t.Errorf("%s %s content type expect %q, actual %q", c.method, c.path, ProblemContentType, contentType)
+		}
+
+		var problem Problem
+		if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+			t.Errorf("%s %s body expect problem details, actual %q", c.method, c.path, rec.Body.String())
+			continue
+		}
+		if problem.Status != c.code || problem.Title != http.StatusText(c.code) || problem.Detail != c.detail {
+			t.Errorf("%s %s problem expect %d %q, actual %+v", c.method, c.path, c.code, c.detail, problem)
+		}
+		if code := problem.Extensions["code"]; code != c.errCode {
+			t.Errorf("%s %s problem code expect %v, actual %v", c.method, c.path, c.errCode, code)
+		}
+	}
+}
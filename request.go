@@ -1,9 +1,12 @@
 package dolphin
 
 import (
+	"bytes"
+	"context"
 	"io"
 	"mime/multipart"
 	"net/http"
+	"strconv"
 	"strings"
 	"sync"
 )
@@ -45,6 +48,40 @@ func (req *Request) Body() string {
 	return *req.body
 }
 
+// BodyReader returns the reader of the raw request body, the body can't be read by Body()
+// after it's consumed.
+func (req *Request) BodyReader() io.ReadCloser {
+	return req.request.Body
+}
+
+// SetBody replaces the request body with the given data, and updates the "Content-Length"
+// header. It's used by the middlewares that transform the request body, like decompressing.
+func (req *Request) SetBody(data []byte) {
+	req.request.Body = io.NopCloser(bytes.NewReader(data))
+	req.request.ContentLength = int64(len(data))
+	req.request.Header.Set("Content-Length", strconv.Itoa(len(data)))
+
+	req.body = nil
+	req.bodyOnce = &sync.Once{}
+}
+
+// ContentLength returns the length of the request body, it's -1 if the length is unknown.
+func (req *Request) ContentLength() int64 {
+	return req.request.ContentLength
+}
+
+// Context returns the context of the request, it's canceled when the client's connection
+// closes or the server is shutting down.
+func (req *Request) Context() context.Context {
+	return req.request.Context()
+}
+
+// SetContext replaces the context of the request, it's used by the middlewares to attach
+// values or deadlines to the request context.
+func (req *Request) SetContext(ctx context.Context) {
+	req.request = req.request.WithContext(ctx)
+}
+
 // Cookie returns the cookie by the specific name.
 func (req *Request) Cookie(key string) (cookie *http.Cookie, err error) {
 	return req.request.Cookie(key)
@@ -60,6 +97,11 @@ func (req *Request) Header(key string) string {
 	return req.request.Header.Get(key)
 }
 
+// DelHeader deletes the values of the specific key from the request header.
+func (req *Request) DelHeader(key string) {
+	req.request.Header.Del(key)
+}
+
 // MultiValuesHeader returns the string array type values from the request header by the
 // specific key.
 func (req *Request) MultiValuesHeader(key string) []string {
@@ -71,6 +113,11 @@ func (req *Request) Host() string {
 	return req.request.Host
 }
 
+// IsTLS reports whether the request is received over a TLS connection.
+func (req *Request) IsTLS() bool {
+	return req.request.TLS != nil
+}
+
 // IP returns the request client ip.
 func (req *Request) IP() string {
 	return req.request.RemoteAddr
@@ -96,6 +143,11 @@ func (req *Request) PostForm(key string) string {
 	return req.request.FormValue(key)
 }
 
+// Proto returns the protocol of the request, like "HTTP/1.1".
+func (req *Request) Proto() string {
+	return req.request.Proto
+}
+
 // Query returns the query string value from the request by the specific key.
 func (req *Request) Query(key string) string {
 	return req.request.FormValue(key)
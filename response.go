@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"io"
 	"net/http"
+	"strconv"
 )
 
 // Response is the HTTP response wrapper.
@@ -15,6 +16,8 @@ type Response struct {
 	header http.Header
 
 	statusCode int
+
+	stream io.Reader
 }
 
 // reset resets response object to initial state.
@@ -23,6 +26,25 @@ func (resp *Response) reset() {
 	resp.cookies = make([]*http.Cookie, 0)
 	resp.header = make(http.Header)
 	resp.statusCode = http.StatusOK
+	resp.stream = nil
+}
+
+// clone returns a deep copy of the response, the streaming body is shared between the
+// responses.
+func (resp *Response) clone() *Response {
+	cloned := &Response{
+		body:       bytes.NewBuffer(append([]byte(nil), resp.body.Bytes()...)),
+		cookies:    make([]*http.Cookie, 0, len(resp.cookies)),
+		header:     resp.header.Clone(),
+		statusCode: resp.statusCode,
+		stream:     resp.stream,
+	}
+
+	for _, cookie := range resp.cookies {
+		cloned.cookies = append(cloned.cookies, cloneCookie(cookie))
+	}
+
+	return cloned
 }
 
 // write writes response to the specific HTTP response writer.
@@ -52,6 +74,49 @@ func (resp *Response) write(rw http.ResponseWriter) {
 
 	// Write response body.
 	io.Copy(rw, resp.body)
+
+	// Write streaming response body if it's set.
+	if resp.stream != nil {
+		io.Copy(rw, resp.stream)
+		resp.closeStream()
+	}
+}
+
+// closeStream closes the streaming body if it implements the io.Closer interface, and removes
+// it from the response.
+func (resp *Response) closeStream() {
+	if closer, ok := resp.stream.(io.Closer); ok {
+		closer.Close()
+	}
+	resp.stream = nil
+}
+
+// Body returns the buffered response body, it doesn't contain the streaming body that set by
+// SetStream.
+func (resp *Response) Body() []byte {
+	return resp.body.Bytes()
+}
+
+// Size returns the size of the response body. For the streaming response, it returns the
+// "Content-Length" header value, or -1 if the header is not set.
+func (resp *Response) Size() int64 {
+	if resp.stream == nil {
+		return int64(resp.body.Len())
+	}
+
+	size, err := strconv.ParseInt(resp.Header("Content-Length"), 10, 64)
+	if err != nil {
+		return -1
+	}
+
+	return size
+}
+
+// ResetBody discards the buffered response body and the streaming body, the streaming body
+// will be closed if it implements the io.Closer interface.
+func (resp *Response) ResetBody() {
+	resp.body.Reset()
+	resp.closeStream()
 }
 
 // SetBody sets response body.
@@ -59,6 +124,19 @@ func (resp *Response) SetBody(data []byte) (len int, err error) {
 	return resp.body.Write(data)
 }
 
+// Stream returns the reader of the streaming response body, it returns nil if the
+// response is not a streaming response.
+func (resp *Response) Stream() io.Reader {
+	return resp.stream
+}
+
+// SetStream sets the reader of the streaming response body, the reader will be
+// copied to the client after the buffered body. The reader will be closed after
+// writing if it implements the io.Closer interface.
+func (resp *Response) SetStream(reader io.Reader) {
+	resp.stream = reader
+}
+
 // AddCookies adds cookies setting to response, it will set response HTTP
 // header "Set-Cookie" field.
 func (resp *Response) AddCookies(cookies ...*http.Cookie) {
@@ -76,6 +154,16 @@ func (resp *Response) AddHeader(key, val string) {
 	resp.header.Add(key, val)
 }
 
+// DelHeader deletes the specific response HTTP header field.
+func (resp *Response) DelHeader(key string) {
+	resp.header.Del(key)
+}
+
+// Header returns the first value of the specific response HTTP header field.
+func (resp *Response) Header(key string) string {
+	return resp.header.Get(key)
+}
+
 // SetHeader sets the specific response HTTP header field.
 func (resp *Response) SetHeader(key, val string) {
 	resp.header.Set(key, val)
@@ -116,6 +204,7 @@ func cloneCookie(cookie *http.Cookie) *http.Cookie {
 		MaxAge:     cookie.MaxAge,
 		Secure:     cookie.Secure,
 		HttpOnly:   cookie.HttpOnly,
+		SameSite:   cookie.SameSite,
 		Raw:        cookie.Raw,
 		Unparsed:   unparsed,
 	}
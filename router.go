@@ -2,31 +2,57 @@ package dolphin
 
 import (
 	"net/http"
+	"sort"
 	"strings"
 	"sync"
 )
 
 type RouterConfig struct {
-	NotFoundHandler HandlerFunc
+	// MethodNotAllowedHandler handles the requests that the path is matched by the routes of
+	// other methods, the "Allow" header is set before it's called. The requests are handled by
+	// the NotFoundHandler if it's not set.
+	MethodNotAllowedHandler HandlerFunc
+	NotFoundHandler         HandlerFunc
 }
 
 type Router struct {
-	NotFoundHandler HandlerFunc
-	handlers        HandlerChain
-	nodeTree        map[string]*routerNode
-	rm              sync.Mutex
+	MethodNotAllowedHandler HandlerFunc
+	NotFoundHandler         HandlerFunc
+	app                     *App
+	handlers                HandlerChain
+	nodeTree                map[string]*routerNode
+	rm                      sync.Mutex
+	routeHooks              []RouteHook
+	routes                  []Route
 }
 
 type routerNode struct {
+	catchAllChild *routerNode
 	children      map[string]*routerNode
 	handlers      HandlerChain
+	pattern       string
 	wildcardChild *routerNode
 	pathVarName   string
 }
 
 // DefaultNotFoundHandler is the default handler for 404 requests.
 func DefaultNotFoundHandler(ctx *Context) {
-	ctx.String("Not Found", http.StatusNotFound)
+	if ctx.ProblemDetailsEnabled() {
+		ctx.Problem(NewProblem(http.StatusNotFound))
+	} else {
+		ctx.String("Not Found", http.StatusNotFound)
+	}
+	ctx.Abort()
+}
+
+// DefaultMethodNotAllowedHandler is the default handler for 405 requests, it's not used unless
+// it's set to the router's MethodNotAllowedHandler.
+func DefaultMethodNotAllowedHandler(ctx *Context) {
+	if ctx.ProblemDetailsEnabled() {
+		ctx.Problem(NewProblem(http.StatusMethodNotAllowed))
+	} else {
+		ctx.String("Method Not Allowed", http.StatusMethodNotAllowed)
+	}
 	ctx.Abort()
 }
 
@@ -35,6 +61,7 @@ func NewRouter(config ...RouterConfig) *Router {
 	router := &Router{
 		NotFoundHandler: DefaultNotFoundHandler,
 		handlers:        make(HandlerChain, 0),
+		nodeTree:        make(map[string]*routerNode),
 		rm:              sync.Mutex{},
 	}
 
@@ -43,6 +70,7 @@ func NewRouter(config ...RouterConfig) *Router {
 		if cfg.NotFoundHandler != nil {
 			router.NotFoundHandler = cfg.NotFoundHandler
 		}
+		router.MethodNotAllowedHandler = cfg.MethodNotAllowedHandler
 	}
 
 	return router
@@ -56,9 +84,15 @@ func (router *Router) Use(handler ...HandlerFunc) *Router {
 	return router
 }
 
-// Routes returns the handler for this router.
+// Routes returns the handler for this router. It panics if the app has OnRoute hooks but the
+// router is not created by the App.NewRouter of the app, as its routes are never reported to
+// the hooks.
 func (router *Router) Routes() HandlerFunc {
 	return func(ctx *Context) {
+		if router.app != ctx.app && ctx.app.hasRouteHooks() {
+			panic("dolphin: the routes of the router are not reported to the OnRoute hooks, create the router by App.NewRouter")
+		}
+
 		pathVariables := make(map[string]string)
 		node := router.getRouterNode(ctx.Method(), ctx.Path(), pathVariables)
 
@@ -72,14 +106,39 @@ func (router *Router) Routes() HandlerFunc {
 				}
 			}
 
+			ctx.routePattern = node.pattern
 			ctx.Use(node.handlers...)
 			ctx.Next()
-		} else if router.NotFoundHandler != nil {
+			return
+		}
+
+		if router.MethodNotAllowedHandler != nil {
+			if allowed := router.allowedMethods(ctx.Path()); len(allowed) > 0 {
+				ctx.SetHeader("Allow", strings.Join(allowed, ", "))
+				router.MethodNotAllowedHandler(ctx)
+				return
+			}
+		}
+
+		if router.NotFoundHandler != nil {
 			router.NotFoundHandler(ctx)
 		}
 	}
 }
 
+// allowedMethods returns the sorted methods that have a route matched the path.
+func (router *Router) allowedMethods(path string) []string {
+	allowed := make([]string, 0)
+	for method := range router.nodeTree {
+		if router.getRouterNode(method, path, make(map[string]string)) != nil {
+			allowed = append(allowed, method)
+		}
+	}
+	sort.Strings(allowed)
+
+	return allowed
+}
+
 // ANY adds specific path with both GET, POST, PUT, DELETE, HEAD, OPTIONS, and PATCH methods into router.
 func (router *Router) ANY(path string, handlers ...HandlerFunc) *Router {
 	router.addRouterNode("DELETE", path, handlers...)
@@ -142,9 +201,25 @@ func (router *Router) PUT(path string, handlers ...HandlerFunc) *Router {
 	return router
 }
 
+// OnRoute registers one or more hooks that called in the order of registration when a route is
+// registered to the router, the hooks are also called for the routes that registered before.
+func (router *Router) OnRoute(hook ...RouteHook) *Router {
+	router.rm.Lock()
+	router.routeHooks = append(router.routeHooks, hook...)
+	routes := append([]Route(nil), router.routes...)
+	router.rm.Unlock()
+
+	for _, route := range routes {
+		for _, h := range hook {
+			h(route)
+		}
+	}
+
+	return router
+}
+
 func (router *Router) addRouterNode(method string, path string, handlers ...HandlerFunc) {
 	router.rm.Lock()
-	defer router.rm.Unlock()
 
 	tree := router.nodeTree[method]
 	if tree == nil {
@@ -154,6 +229,20 @@ func (router *Router) addRouterNode(method string, path string, handlers ...Hand
 	}
 
 	tree.addRouterNode(path, handlers...)
+
+	route := Route{
+		Handlers: append(HandlerChain{}, handlers...),
+		Method:   method,
+		Path:     "/" + strings.Trim(path, "/"),
+	}
+	router.routes = append(router.routes, route)
+	hooks := append([]RouteHook(nil), router.routeHooks...)
+
+	router.rm.Unlock()
+
+	for _, hook := range hooks {
+		hook(route)
+	}
 }
 
 func (router *Router) getRouterNode(method string, path string, pathVariables map[string]string) *routerNode {
@@ -163,24 +252,68 @@ func (router *Router) getRouterNode(method string, path string, pathVariables ma
 	}
 
 	paths := resolvePath(path)
-	for _, p := range paths {
+	// The nearest node that has a catch-all child, the index of the path segment that the
+	// catch-all child starts from, and the number of the path variables that are set before it.
+	var fallback *routerNode
+	fallbackIndex, fallbackVars := 0, 0
+	varNames := make([]string, 0)
+
+	backtrack := func() *routerNode {
+		if fallback == nil {
+			return nil
+		}
+
+		// Remove the path variables of the nodes under the fallback node.
+		for _, name := range varNames[fallbackVars:] {
+			delete(pathVariables, name)
+		}
+
+		return fallback.matchCatchAll(paths[fallbackIndex:], pathVariables)
+	}
+
+	for i, p := range paths {
+		if node.catchAllChild != nil {
+			fallback, fallbackIndex, fallbackVars = node, i, len(varNames)
+		}
+
 		child := node.children[p]
 
 		if child == nil {
 			if node.wildcardChild == nil {
-				return nil
+				return backtrack()
 			}
 
 			child = node.wildcardChild
 			pathVariables[child.pathVarName] = p
+			varNames = append(varNames, child.pathVarName)
 		}
 
 		node = child
 	}
 
+	if node.handlers == nil {
+		if node.catchAllChild != nil {
+			fallback, fallbackIndex, fallbackVars = node, len(paths), len(varNames)
+		}
+
+		return backtrack()
+	}
+
 	return node
 }
 
+// matchCatchAll returns the catch-all child of the node and sets the rest of the path as its
+// path variable, or returns nil if the node has no catch-all child.
+func (node *routerNode) matchCatchAll(paths []string, pathVariables map[string]string) *routerNode {
+	if node.catchAllChild == nil {
+		return nil
+	}
+
+	pathVariables[node.catchAllChild.pathVarName] = strings.Join(paths, "/")
+
+	return node.catchAllChild
+}
+
 func (root *routerNode) addRouterNode(path string, handler ...HandlerFunc) {
 	var child *routerNode
 	node := root
@@ -188,10 +321,14 @@ func (root *routerNode) addRouterNode(path string, handler ...HandlerFunc) {
 
 	for _, p := range paths {
 		isWildcard := false
+		isCatchAll := false
 
 		if strings.HasPrefix(p, ":") {
 			child = node.wildcardChild
 			isWildcard = true
+		} else if strings.HasPrefix(p, "*") {
+			child = node.catchAllChild
+			isCatchAll = true
 		} else {
 			child = node.children[p]
 		}
@@ -202,15 +339,25 @@ func (root *routerNode) addRouterNode(path string, handler ...HandlerFunc) {
 
 			if isWildcard {
 				node.wildcardChild = child
-				node.pathVarName = p[1:]
+				child.pathVarName = p[1:]
+			} else if isCatchAll {
+				node.catchAllChild = child
+				child.pathVarName = p[1:]
 			} else {
 				node.children[p] = child
 			}
 		}
 
 		node = child
+
+		if isCatchAll {
+			// The catch-all segment matches the rest of the path, the following segments are
+			// ignored.
+			break
+		}
 	}
 
+	node.pattern = "/" + strings.Trim(path, "/")
 	node.handlers = make(HandlerChain, 0, len(handler))
 	if len(handler) > 0 {
 		node.handlers = append(node.handlers, handler...)
@@ -0,0 +1,116 @@
+package dolphin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRouterPathVariables(t *testing.T) {
+	router := NewRouter()
+	router.GET("/users/:id", func(c *Context) {
+		c.String("user " + c.PathVariable("id"))
+	})
+	router.GET("/users/:id/posts/:post", func(c *Context) {
+		c.String("user " + c.PathVariable("id") + " post " + c.PathVariable("post"))
+	})
+	router.GET("/files/*path", func(c *Context) {
+		c.String("file " + c.PathVariable("path"))
+	})
+
+	app := New(nil)
+	app.Use(router.Routes())
+
+	cases := []struct {
+		path     string
+		code     int
+		expected string
+	}{
+		{"/users/1", http.StatusOK, "user 1"},
+		{"/users", http.StatusNotFound, "Not Found"},
+		{"/users/1/posts/2", http.StatusOK, "user 1 post 2"},
+		{"/users/1/posts", http.StatusNotFound, "Not Found"},
+		{"/files/a/b.txt", http.StatusOK, "file a/b.txt"},
+		{"/files/", http.StatusOK, "file "},
+		{"/unknown", http.StatusNotFound, "Not Found"},
+	}
+
+	for _, c := range cases {
+		rec := testServe(app, httptest.NewRequest(http.MethodGet, c.path, nil))
+
+		if rec.Code != c.code || rec.Body.String() != c.expected {
+			t.Errorf("GET %s expect %d %q, actual %d %q", c.path, c.code, c.expected, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestRouterCatchAllBacktrack(t *testing.T) {
+	router := NewRouter()
+	router.GET("/assets/*filepath", func(c *Context) {
+		c.String("asset " + c.PathVariable("filepath") + " " + c.PathVariable("name"))
+	})
+	router.GET("/assets/img/logo.png", func(c *Context) {
+		c.String("logo")
+	})
+	router.GET("/assets/users/:name/avatar.png", func(c *Context) {
+		c.String("avatar " + c.PathVariable("name"))
+	})
+
+	app := New(nil)
+	app.Use(router.Routes())
+
+	cases := []struct {
+		path     string
+		expected string
+	}{
+		{"/assets/img/logo.png", "logo"},
+		{"/assets/img/other.png", "asset img/other.png "},
+		{"/assets/img", "asset img "},
+		{"/assets/users/1/avatar.png", "avatar 1"},
+		{"/assets/users/1/other.png", "asset users/1/other.png "},
+	}
+
+	for _, c := range cases {
+		rec := testServe(app, httptest.NewRequest(http.MethodGet, c.path, nil))
+
+		if rec.Code != http.StatusOK || rec.Body.String() != c.expected {
+			t.Errorf("GET %s expect 200 %q, actual %d %q", c.path, c.expected, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestRouterMethodNotAllowed(t *testing.T) {
+	router := NewRouter(RouterConfig{MethodNotAllowedHandler: DefaultMethodNotAllowedHandler})
+	router.GET("/users/:id", func(c *Context) {
+		c.String("get")
+	})
+	router.DELETE("/users/:id", func(c *Context) {
+		c.String("delete")
+	})
+
+	app := New(nil)
+	app.Use(router.Routes())
+
+	cases := []struct {
+		method   string
+		path     string
+		code     int
+		allow    string
+		expected string
+	}{
+		{http.MethodGet, "/users/1", http.StatusOK, "", "get"},
+		{http.MethodPost, "/users/1", http.StatusMethodNotAllowed, "DELETE, GET", "Method Not Allowed"},
+		{http.MethodPost, "/unknown", http.StatusNotFound, "", "Not Found"},
+	}
+
+	for _, c := range cases {
+		rec := testServe(app, httptest.NewRequest(c.method, c.path, nil))
+
+		if rec.Code != c.code || rec.Body.String() != c.expected {
+			t.Errorf("%s %s expect %d %q, actual %d %q", c.method, c.path, c.code, c.expected, rec.Code, rec.Body.String())
+		}
+		if allow := rec.Header().Get("Allow"); allow != c.allow {
+			t.Errorf("%s %s Allow expect %q, actual %q", c.method, c.path, c.allow, allow)
+		}
+	}
+}
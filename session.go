@@ -0,0 +1,312 @@
+package dolphin
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// sessionStateKey is the key of the session holder in the context state.
+const sessionStateKey = "dolphin.session"
+
+// flashKeyPrefix is the prefix of the keys that store the flash messages in the session.
+const flashKeyPrefix = "_flash."
+
+// Session is the session of the request, it's a map of values with typed getters. The session
+// is loaded and persisted by the session middleware, and it's saved only if it's modified.
+type Session struct {
+	destroyed  bool
+	id         string
+	isNew      bool
+	modified   bool
+	mu         sync.RWMutex
+	previousID string
+	values     map[string]any
+}
+
+// sessionHolder holds the session of the request, and loads it at the first access.
+type sessionHolder struct {
+	load    func(ctx *Context) *Session
+	mu      sync.Mutex
+	session *Session
+}
+
+// NewSession creates a session with the ID and the values. It creates a new session with a
+// random ID if the ID is empty. It's used by the session stores.
+func NewSession(id string, values map[string]any) *Session {
+	session := &Session{
+		id:     id,
+		values: values,
+	}
+
+	if session.id == "" {
+		session.id = NewSessionID()
+		session.isNew = true
+	}
+	if session.values == nil {
+		session.values = make(map[string]any)
+	}
+
+	return session
+}
+
+// NewSessionID returns a random session ID, it has 256 bits of entropy.
+func NewSessionID() string {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		panic(err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
+
+// Session returns the session of the request, it'll be loaded at the first call. It returns nil
+// if the session middleware is not used.
+//
+//	session := ctx.Session()
+//	session.Set("user_id", user.ID)
+func (ctx *Context) Session() *Session {
+	val, ok := ctx.Get(sessionStateKey)
+	if !ok {
+		return nil
+	}
+
+	holder := val.(*sessionHolder)
+	holder.mu.Lock()
+	defer holder.mu.Unlock()
+
+	if holder.session == nil {
+		holder.session = holder.load(ctx)
+	}
+
+	return holder.session
+}
+
+// LoadedSession returns the session of the request if it has been loaded by Session, or nil if
+// it's not accessed or the session middleware is not used. It doesn't load the session, so the
+// session middleware uses it to save only the accessed sessions.
+func (ctx *Context) LoadedSession() *Session {
+	val, ok := ctx.Get(sessionStateKey)
+	if !ok {
+		return nil
+	}
+
+	holder := val.(*sessionHolder)
+	holder.mu.Lock()
+	defer holder.mu.Unlock()
+
+	return holder.session
+}
+
+// SetSessionLoader sets the function to load the session of the request, the function will be
+// called with the context when the session is first accessed by Session, and the concurrent
+// accesses wait for the same loading. It's used by the session middleware.
+func (ctx *Context) SetSessionLoader(load func(ctx *Context) *Session) {
+	ctx.Set(sessionStateKey, &sessionHolder{load: load})
+}
+
+// ID returns the session ID.
+func (session *Session) ID() string {
+	session.mu.RLock()
+	defer session.mu.RUnlock()
+
+	return session.id
+}
+
+// IsNew reports whether the session is created in this request.
+func (session *Session) IsNew() bool {
+	return session.isNew
+}
+
+// Get returns the value of the key in the session.
+func (session *Session) Get(key string) (any, bool) {
+	session.mu.RLock()
+	defer session.mu.RUnlock()
+
+	val, ok := session.values[key]
+
+	return val, ok
+}
+
+// GetString returns the string value of the key, or an empty string if the value is not set
+// or not a string.
+func (session *Session) GetString(key string) string {
+	val, _ := session.Get(key)
+	str, _ := val.(string)
+
+	return str
+}
+
+// GetInt returns the int value of the key, or zero if the value is not set or not an int.
+func (session *Session) GetInt(key string) int {
+	val, _ := session.Get(key)
+	i, _ := val.(int)
+
+	return i
+}
+
+// GetBool returns the bool value of the key, or false if the value is not set or not a bool.
+func (session *Session) GetBool(key string) bool {
+	val, _ := session.Get(key)
+	b, _ := val.(bool)
+
+	return b
+}
+
+// Set sets the value of the key in the session. The values are encoded by encoding/gob in the
+// cookie store, so the custom types should be registered by gob.Register.
+func (session *Session) Set(key string, val any) {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	session.values[key] = val
+	session.modified = true
+}
+
+// Delete deletes the value of the key in the session.
+func (session *Session) Delete(key string) {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	if _, ok := session.values[key]; ok {
+		delete(session.values, key)
+		session.modified = true
+	}
+}
+
+// Clear deletes all values in the session.
+func (session *Session) Clear() {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	if len(session.values) > 0 {
+		session.values = make(map[string]any)
+		session.modified = true
+	}
+}
+
+// Keys returns the sorted keys of the values in the session, the flash messages are excluded.
+func (session *Session) Keys() []string {
+	session.mu.RLock()
+	defer session.mu.RUnlock()
+
+	keys := make([]string, 0, len(session.values))
+	for key := range session.values {
+		if !strings.HasPrefix(key, flashKeyPrefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// AddFlash adds a flash message to the session, the message will be removed after it's read by
+// Flashes. The category is "default" if it's not set.
+//
+//	ctx.Session().AddFlash("Profile updated.")
+//	ctx.Redirect("/profile")
+func (session *Session) AddFlash(message string, category ...string) {
+	key := flashKeyPrefix + flashCategory(category)
+
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	messages, _ := session.values[key].([]string)
+	session.values[key] = append(messages, message)
+	session.modified = true
+}
+
+// Flashes returns and removes the flash messages of the category, the category is "default" if
+// it's not set.
+func (session *Session) Flashes(category ...string) []string {
+	key := flashKeyPrefix + flashCategory(category)
+
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	messages, ok := session.values[key].([]string)
+	if !ok {
+		return nil
+	}
+
+	delete(session.values, key)
+	session.modified = true
+
+	return messages
+}
+
+// Regenerate changes the session ID and keeps the values, the previous session will be deleted
+// from the store. It should be called after the privilege level changes, like logging in, to
+// prevent the session fixation attacks.
+func (session *Session) Regenerate() {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	if session.previousID == "" && !session.isNew {
+		session.previousID = session.id
+	}
+	session.id = NewSessionID()
+	session.modified = true
+}
+
+// Destroy deletes all values of the session, and the session will be deleted from the store and
+// the client.
+func (session *Session) Destroy() {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	session.values = make(map[string]any)
+	session.destroyed = true
+}
+
+// IsModified reports whether the session values or ID are changed. It's used by the session
+// middleware to save the session only if it's necessary.
+func (session *Session) IsModified() bool {
+	session.mu.RLock()
+	defer session.mu.RUnlock()
+
+	return session.modified
+}
+
+// IsDestroyed reports whether the session is destroyed by Destroy.
+func (session *Session) IsDestroyed() bool {
+	session.mu.RLock()
+	defer session.mu.RUnlock()
+
+	return session.destroyed
+}
+
+// PreviousID returns the session ID before it's regenerated, or an empty string if the ID is not
+// regenerated or the session is new.
+func (session *Session) PreviousID() string {
+	session.mu.RLock()
+	defer session.mu.RUnlock()
+
+	return session.previousID
+}
+
+// Values returns a copy of the session values, including the flash messages. It's used by the
+// session stores to persist the session.
+func (session *Session) Values() map[string]any {
+	session.mu.RLock()
+	defer session.mu.RUnlock()
+
+	values := make(map[string]any, len(session.values))
+	for k, v := range session.values {
+		values[k] = v
+	}
+
+	return values
+}
+
+// flashCategory returns the flash category in the optional parameter.
+func flashCategory(category []string) string {
+	if len(category) > 0 && category[0] != "" {
+		return category[0]
+	}
+
+	return "default"
+}
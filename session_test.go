@@ -0,0 +1,92 @@
+package dolphin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestSession(t *testing.T) {
+	session := NewSession("", nil)
+	if !session.IsNew() || session.ID() == "" || session.IsModified() {
+		t.Fatalf("New session expect new and unmodified with ID, actual %v %q %v", session.IsNew(), session.ID(), session.IsModified())
+	}
+
+	session.Set("name", "dolphin")
+	session.Set("count", 1)
+	session.Set("admin", true)
+	if session.GetString("name") != "dolphin" || session.GetInt("count") != 1 || !session.GetBool("admin") {
+		t.Errorf("Typed getters expect set values, actual %v", session.Values())
+	}
+	if session.GetInt("name") != 0 {
+		t.Errorf("GetInt with string value expect 0, actual %d", session.GetInt("name"))
+	}
+
+	session.AddFlash("saved")
+	session.AddFlash("failed", "error")
+	if keys := session.Keys(); !reflect.DeepEqual(keys, []string{"admin", "count", "name"}) {
+		t.Errorf("Keys expect exclude flashes, actual %v", keys)
+	}
+	if flashes := session.Flashes(); !reflect.DeepEqual(flashes, []string{"saved"}) {
+		t.Errorf("Flashes expect [saved], actual %v", flashes)
+	}
+	if flashes := session.Flashes(); flashes != nil {
+		t.Errorf("Flashes expect removed after read, actual %v", flashes)
+	}
+	if flashes := session.Flashes("error"); !reflect.DeepEqual(flashes, []string{"failed"}) {
+		t.Errorf("Flashes(\"error\") expect [failed], actual %v", flashes)
+	}
+
+	stored := NewSession("sid", map[string]any{"name": "dolphin"})
+	stored.Regenerate()
+	if stored.ID() == "sid" || stored.PreviousID() != "sid" || stored.GetString("name") != "dolphin" {
+		t.Errorf("Regenerate expect new ID with values kept, actual %q %q %v", stored.ID(), stored.PreviousID(), stored.Values())
+	}
+}
+
+func TestContextSessionLoader(t *testing.T) {
+	testRequest(func(ctx *Context) {
+		if ctx.Session() != nil {
+			t.Errorf("Session without loader expect nil")
+		}
+
+		var loads int32
+		ctx.SetSessionLoader(func(c *Context) *Session {
+			if c != ctx {
+				t.Errorf("Session loader expect the request context")
+			}
+			atomic.AddInt32(&loads, 1)
+			return NewSession("", nil)
+		})
+
+		if ctx.LoadedSession() != nil {
+			t.Errorf("Loaded session before access expect nil")
+		}
+
+		var wg sync.WaitGroup
+		sessions := make([]*Session, 8)
+		for i := range sessions {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				sessions[i] = ctx.Session()
+			}(i)
+		}
+		wg.Wait()
+
+		for _, session := range sessions {
+			if session != sessions[0] {
+				t.Errorf("Concurrent sessions expect the same session")
+			}
+		}
+		if loads != 1 {
+			t.Errorf("Session expect loaded once, actual %d", loads)
+		}
+		if ctx.LoadedSession() != sessions[0] {
+			t.Errorf("Loaded session expect the accessed session")
+		}
+	}, httptest.NewRequest(http.MethodGet, "/", nil))
+}
@@ -0,0 +1,509 @@
+package dolphin
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SPAConfig is the configuration for serving single-page applications.
+type SPAConfig struct {
+	// ExcludePrefixes are the path prefixes that will not fall back to the index file, for
+	// example, "/api/". The requests with these prefixes are handled by the router's
+	// NotFoundHandler if no routes matched.
+	ExcludePrefixes []string
+	// Immutable reports whether the file is an asset with the content hash in its name, like
+	// "app.3f2a1b9c.js". The immutable assets are cached by clients for MaxAge, and the other
+	// files must be revalidated before using the cached copies. Default matches the file
+	// names that have a hash segment at least 8 characters with digits.
+	Immutable func(name string) bool
+	// Index is the index file of the application, default "index.html".
+	Index string
+	// MaxAge is the max age of the immutable assets, default one year.
+	MaxAge time.Duration
+}
+
+// StaticConfig is the configuration for serving static files.
+type StaticConfig struct {
+	// Browse enables the directory listing if the requested directory has no index file.
+	Browse bool
+	// Index is the index file name of directories, default "index.html".
+	Index string
+}
+
+// readCloser combines a reader and a closer, it's used to close the file after the limited
+// reader is read.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// Static serves the files in the given directory with the path prefix, for example,
+// `router.Static("/assets", "./public")` serves "./public/app.js" at "/assets/app.js".
+func (router *Router) Static(prefix, root string, config ...StaticConfig) *Router {
+	return router.StaticFS(prefix, os.DirFS(root), config...)
+}
+
+// StaticFS serves the files in the given file system with the path prefix, it can be used
+// to serve the files that embedded by the embed.FS. The missing files are handled by the
+// router's NotFoundHandler, or DefaultNotFoundHandler if it's not set.
+func (router *Router) StaticFS(prefix string, fsys fs.FS, config ...StaticConfig) *Router {
+	cfg := getStaticConfig(config...)
+
+	handler := func(ctx *Context) {
+		err := ctx.serveFS(fsys, ctx.PathVariable("filepath"), &cfg)
+		if err == nil {
+			return
+		}
+
+		if isFileNotFound(err) {
+			if router.NotFoundHandler != nil {
+				router.NotFoundHandler(ctx)
+			} else {
+				DefaultNotFoundHandler(ctx)
+			}
+			return
+		}
+
+		debugPrintf("Failed to serve static file: %v", err)
+		ctx.String("Internal Server Error", http.StatusInternalServerError)
+	}
+
+	pattern := strings.TrimSuffix(prefix, "/") + "/*filepath"
+	router.GET(pattern, handler)
+	router.HEAD(pattern, handler)
+
+	return router
+}
+
+// SPA serves a single-page application from the file system. It serves the existing files,
+// and responds the index file for the unmatched GET and HEAD requests that accept HTML, so
+// the client-side router can handle them. Other unmatched requests are still handled by the
+// router's NotFoundHandler.
+//
+// SPA takes over the router's NotFoundHandler, so it should be called after the
+// NotFoundHandler is set.
+func (router *Router) SPA(fsys fs.FS, config ...SPAConfig) *Router {
+	cfg := getSPAConfig(config...)
+	notFound := router.NotFoundHandler
+	immutableCacheControl := fmt.Sprintf("public, max-age=%d, immutable", int64(cfg.MaxAge.Seconds()))
+
+	handleNotFound := func(ctx *Context) {
+		if notFound != nil {
+			notFound(ctx)
+		}
+	}
+
+	router.NotFoundHandler = func(ctx *Context) {
+		method := ctx.Method()
+		if method != http.MethodGet && method != http.MethodHead {
+			handleNotFound(ctx)
+			return
+		}
+
+		urlPath := ctx.Path()
+		for _, prefix := range cfg.ExcludePrefixes {
+			if strings.HasPrefix(urlPath, prefix) {
+				handleNotFound(ctx)
+				return
+			}
+		}
+
+		name, ok := cleanFSPath(urlPath)
+		isIndex := ok && (name == "." || name == cfg.Index)
+
+		if ok && !isIndex {
+			cacheControl := "no-cache"
+			if cfg.Immutable(name) {
+				cacheControl = immutableCacheControl
+			}
+			ctx.SetHeader("Cache-Control", cacheControl)
+
+			err := ctx.SendFileFS(fsys, name)
+			if err == nil {
+				return
+			}
+			ctx.Response.DelHeader("Cache-Control")
+
+			if !isFileNotFound(err) && err != ErrFileIsDirectory {
+				debugPrintf("Failed to serve static file: %v", err)
+				ctx.String("Internal Server Error", http.StatusInternalServerError)
+				return
+			}
+		}
+
+		if !isIndex && !acceptsHTML(ctx) {
+			handleNotFound(ctx)
+			return
+		}
+
+		// The index file must be revalidated, so the clients can get the new version of the
+		// application after deploying.
+		ctx.SetHeader("Cache-Control", "no-cache")
+		if err := ctx.SendFileFS(fsys, cfg.Index); err != nil {
+			ctx.Response.DelHeader("Cache-Control")
+			debugPrintf("Failed to serve SPA index file: %v", err)
+			handleNotFound(ctx)
+		}
+	}
+
+	return router
+}
+
+// SendFile writes the named file to the response body. It sets the content type by the file
+// extension or the file content, and handles the range and conditional requests.
+func (ctx *Context) SendFile(name string) error {
+	if containsDotDot(name) {
+		return ErrInvalidFilePath
+	}
+
+	file, err := os.Open(filepath.Clean(name))
+	if err != nil {
+		return err
+	}
+
+	return ctx.serveFile(name, file)
+}
+
+// SendFileFS writes the named file in the file system to the response body, it works like
+// SendFile.
+func (ctx *Context) SendFileFS(fsys fs.FS, name string) error {
+	name, ok := cleanFSPath(name)
+	if !ok {
+		return ErrInvalidFilePath
+	}
+
+	file, err := fsys.Open(name)
+	if err != nil {
+		return err
+	}
+
+	return ctx.serveFile(name, file)
+}
+
+// Attachment writes the named file to the response body, and prompts the client to save it
+// with the given file name. It'll use the base name of the file if the name is empty.
+func (ctx *Context) Attachment(file, name string) error {
+	if name == "" {
+		name = filepath.Base(file)
+	}
+
+	disposition := mime.FormatMediaType("attachment", map[string]string{"filename": name})
+	if disposition == "" {
+		disposition = "attachment"
+	}
+	ctx.SetHeader("Content-Disposition", disposition)
+
+	return ctx.SendFile(file)
+}
+
+// serveFS serves the named file or directory from the file system for the static handler.
+func (ctx *Context) serveFS(fsys fs.FS, name string, cfg *StaticConfig) error {
+	name, ok := cleanFSPath(name)
+	if !ok {
+		return ErrInvalidFilePath
+	}
+
+	file, err := fsys.Open(name)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	if !info.IsDir() {
+		return ctx.serveContent(name, file, info)
+	}
+	file.Close()
+
+	// Redirect to the path with the trailing slash to make the relative links in the index
+	// file work.
+	if urlPath := ctx.Path(); !strings.HasSuffix(urlPath, "/") {
+		target := path.Base(urlPath) + "/"
+		if query := ctx.RawQuery(); query != "" {
+			target += "?" + query
+		}
+		ctx.Redirect(target, http.StatusMovedPermanently)
+		return nil
+	}
+
+	index := path.Join(name, cfg.Index)
+	if indexFile, err := fsys.Open(index); err == nil {
+		indexInfo, err := indexFile.Stat()
+		if err == nil && !indexInfo.IsDir() {
+			return ctx.serveContent(index, indexFile, indexInfo)
+		}
+		indexFile.Close()
+	}
+
+	if !cfg.Browse {
+		return fs.ErrNotExist
+	}
+
+	return ctx.listDirectory(fsys, name)
+}
+
+// serveFile serves the opened file, it returns an error if the file is a directory.
+func (ctx *Context) serveFile(name string, file fs.File) error {
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	if info.IsDir() {
+		file.Close()
+		return ErrFileIsDirectory
+	}
+
+	return ctx.serveContent(name, file, info)
+}
+
+// serveContent writes the file content to the response, it'll close the file after the
+// content is written.
+func (ctx *Context) serveContent(name string, file fs.File, info fs.FileInfo) error {
+	size := info.Size()
+	modtime := info.ModTime()
+
+	if !isZeroTime(modtime) {
+		ctx.SetHeader("Last-Modified", modtime.UTC().Format(http.TimeFormat))
+	}
+
+	etag := ctx.Response.Header("ETag")
+	if etag == "" {
+		etag = fmt.Sprintf(`W/"%x-%x"`, modtime.UnixNano(), size)
+		ctx.SetHeader("ETag", etag)
+	}
+
+	switch checkPreconditions(ctx.Request.request, etag, modtime) {
+	case conditionNotModified:
+		file.Close()
+		ctx.Response.DelHeader("Content-Disposition")
+		return ctx.SetStatusCode(http.StatusNotModified)
+	case conditionFailed:
+		file.Close()
+		return ctx.SetStatusCode(http.StatusPreconditionFailed)
+	}
+
+	var reader io.Reader = file
+	seeker, isSeeker := file.(io.Seeker)
+
+	contentType := mime.TypeByExtension(path.Ext(name))
+	if contentType == "" {
+		buf := make([]byte, 512)
+		n, _ := io.ReadFull(file, buf)
+		contentType = http.DetectContentType(buf[:n])
+
+		if isSeeker {
+			if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+				file.Close()
+				return err
+			}
+		} else {
+			reader = io.MultiReader(bytes.NewReader(buf[:n]), file)
+		}
+	}
+
+	ctx.SetHeader("Accept-Ranges", "bytes")
+
+	start, length, statusCode := int64(0), size, http.StatusOK
+	if rangeHeader := ctx.Header("Range"); rangeHeader != "" &&
+		checkIfRange(ctx.Request.request, etag, modtime) {
+		ranges, err := parseRange(rangeHeader, size)
+		if err == errNoOverlap {
+			file.Close()
+			ctx.SetHeader("Content-Range", fmt.Sprintf("bytes */%d", size))
+			return ctx.String("Range Not Satisfiable", http.StatusRequestedRangeNotSatisfiable)
+		}
+
+		// Multiple ranges are not supported, the server ignores the "Range" header and sends
+		// the full content in this case.
+		if err == nil && len(ranges) == 1 {
+			start, length = ranges[0].start, ranges[0].length
+			statusCode = http.StatusPartialContent
+			ctx.SetHeader("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, start+length-1, size))
+		}
+	}
+
+	if start > 0 {
+		var err error
+		if isSeeker {
+			_, err = seeker.Seek(start, io.SeekStart)
+		} else {
+			_, err = io.CopyN(io.Discard, reader, start)
+		}
+		if err != nil {
+			file.Close()
+			return err
+		}
+	}
+
+	ctx.SetHeader("Content-Length", strconv.FormatInt(length, 10))
+
+	if ctx.Method() == http.MethodHead {
+		file.Close()
+		return ctx.send(nil, contentType, statusCode)
+	}
+
+	return ctx.Stream(contentType, readCloser{io.LimitReader(reader, length), file}, statusCode)
+}
+
+// listDirectory writes the directory listing page of the named directory.
+func (ctx *Context) listDirectory(fsys fs.FS, name string) error {
+	entries, err := fs.ReadDir(fsys, name)
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+
+	buf := new(bytes.Buffer)
+	buf.WriteString("<!doctype html>\n<meta name=\"viewport\" content=\"width=device-width\">\n<pre>\n")
+	for _, entry := range entries {
+		entryName := entry.Name()
+		if entry.IsDir() {
+			entryName += "/"
+		}
+
+		link := url.URL{Path: entryName}
+		fmt.Fprintf(buf, "<a href=\"%s\">%s</a>\n", link.String(), html.EscapeString(entryName))
+	}
+	buf.WriteString("</pre>\n")
+
+	return ctx.HTML(buf.String())
+}
+
+// acceptsHTML reports whether the request accepts HTML response by the "Accept" header, it's
+// used to distinguish the browser navigations from the API requests.
+func acceptsHTML(ctx *Context) bool {
+	for _, accept := range ctx.MultiValuesHeader("Accept") {
+		for _, mediaRange := range strings.Split(accept, ",") {
+			mediaType, _, _ := strings.Cut(mediaRange, ";")
+			mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+
+			if mediaType == "text/html" || mediaType == "application/xhtml+xml" {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// isHashedAsset reports whether the file name contains a content hash segment, like
+// "app.3f2a1b9c.js" or "index-BPvgi06w.js".
+func isHashedAsset(name string) bool {
+	base := path.Base(name)
+	ext := path.Ext(base)
+	if ext == "" {
+		return false
+	}
+	base = strings.TrimSuffix(base, ext)
+
+	i := strings.LastIndexAny(base, ".-")
+	if i < 0 {
+		return false
+	}
+
+	hash := base[i+1:]
+	if len(hash) < 8 || !strings.ContainsAny(hash, "0123456789") {
+		return false
+	}
+
+	for _, r := range hash {
+		if !(r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r == '_') {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isFileNotFound reports whether the error means the file is not found or can't be served.
+func isFileNotFound(err error) bool {
+	return errors.Is(err, fs.ErrNotExist) || errors.Is(err, fs.ErrPermission) ||
+		errors.Is(err, ErrInvalidFilePath)
+}
+
+// cleanFSPath cleans the path and converts it to a valid fs.FS path. It returns false if the
+// path contains invalid characters.
+func cleanFSPath(name string) (string, bool) {
+	if strings.ContainsAny(name, "\\\x00") {
+		return "", false
+	}
+
+	name = strings.TrimPrefix(path.Clean("/"+name), "/")
+	if name == "" {
+		name = "."
+	}
+
+	return name, fs.ValidPath(name)
+}
+
+// containsDotDot reports whether the path contains ".." as a path element.
+func containsDotDot(name string) bool {
+	if !strings.Contains(name, "..") {
+		return false
+	}
+
+	for _, elem := range strings.FieldsFunc(name, func(r rune) bool { return r == '/' || r == '\\' }) {
+		if elem == ".." {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getSPAConfig returns the SPA config with default values.
+func getSPAConfig(config ...SPAConfig) SPAConfig {
+	cfg := SPAConfig{}
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+
+	if cfg.Immutable == nil {
+		cfg.Immutable = isHashedAsset
+	}
+	if cfg.Index == "" {
+		cfg.Index = "index.html"
+	}
+	if cfg.MaxAge <= 0 {
+		cfg.MaxAge = 365 * 24 * time.Hour
+	}
+
+	return cfg
+}
+
+// getStaticConfig returns the static config with default values.
+func getStaticConfig(config ...StaticConfig) StaticConfig {
+	cfg := StaticConfig{}
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+
+	if cfg.Index == "" {
+		cfg.Index = "index.html"
+	}
+
+	return cfg
+}
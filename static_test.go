@@ -0,0 +1,253 @@
+package dolphin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// testStaticApp creates an app that serves the test file system at "/assets".
+func testStaticApp(config ...StaticConfig) *App {
+	modtime := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	fsys := fstest.MapFS{
+		"app.js":           {Data: []byte("console.log('dolphin');"), ModTime: modtime},
+		"docs/index.html":  {Data: []byte("<h1>Docs</h1>"), ModTime: modtime},
+		"images/logo":      {Data: []byte("\x89PNG\x0d\x0a\x1a\x0a"), ModTime: modtime},
+		"images/<a>.txt":   {Data: []byte("a"), ModTime: modtime},
+		"images/other.txt": {Data: []byte("other"), ModTime: modtime},
+	}
+
+	router := NewRouter()
+	router.StaticFS("/assets", fsys, config...)
+
+	app := New(nil)
+	app.Use(router.Routes())
+
+	return app
+}
+
+func TestStaticFS(t *testing.T) {
+	app := testStaticApp()
+
+	rec := testServe(app, httptest.NewRequest(http.MethodGet, "/assets/app.js", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Status code expect %d, actual %d", http.StatusOK, rec.Code)
+	}
+	if body := rec.Body.String(); body != "console.log('dolphin');" {
+		t.Errorf("Body expect file content, actual %q", body)
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/javascript") {
+		t.Errorf("Content type expect text/javascript, actual %q", ct)
+	}
+	etag := rec.Header().Get("ETag")
+	if etag == "" {
+		t.Errorf("ETag expect not empty")
+	}
+
+	// Content type sniffing
+	rec = testServe(app, httptest.NewRequest(http.MethodGet, "/assets/images/logo", nil))
+	if ct := rec.Header().Get("Content-Type"); ct != "image/png" {
+		t.Errorf("Content type expect image/png, actual %q", ct)
+	}
+
+	// If-None-Match
+	req := httptest.NewRequest(http.MethodGet, "/assets/app.js", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec = testServe(app, req)
+	if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+		t.Errorf("If-None-Match expect 304 without body, actual %d %q", rec.Code, rec.Body.String())
+	}
+
+	// If-Modified-Since
+	req = httptest.NewRequest(http.MethodGet, "/assets/app.js", nil)
+	req.Header.Set("If-Modified-Since", rec.Header().Get("Last-Modified"))
+	rec = testServe(app, req)
+	if rec.Code != http.StatusNotModified {
+		t.Errorf("If-Modified-Since expect 304, actual %d", rec.Code)
+	}
+
+	// Range
+	req = httptest.NewRequest(http.MethodGet, "/assets/app.js", nil)
+	req.Header.Set("Range", "bytes=0-6")
+	rec = testServe(app, req)
+	if rec.Code != http.StatusPartialContent || rec.Body.String() != "console" {
+		t.Errorf("Range expect 206 \"console\", actual %d %q", rec.Code, rec.Body.String())
+	}
+	if cr := rec.Header().Get("Content-Range"); cr != "bytes 0-6/23" {
+		t.Errorf("Content-Range expect \"bytes 0-6/23\", actual %q", cr)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/assets/app.js", nil)
+	req.Header.Set("Range", "bytes=-3")
+	rec = testServe(app, req)
+	if rec.Body.String() != "');" {
+		t.Errorf("Suffix range expect \"');\", actual %q", rec.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/assets/app.js", nil)
+	req.Header.Set("Range", "bytes=100-")
+	rec = testServe(app, req)
+	if rec.Code != http.StatusRequestedRangeNotSatisfiable {
+		t.Errorf("Unsatisfiable range expect 416, actual %d", rec.Code)
+	}
+
+	// HEAD
+	rec = testServe(app, httptest.NewRequest(http.MethodHead, "/assets/app.js", nil))
+	if rec.Code != http.StatusOK || rec.Body.Len() != 0 {
+		t.Errorf("HEAD expect 200 without body, actual %d %q", rec.Code, rec.Body.String())
+	}
+	if cl := rec.Header().Get("Content-Length"); cl != "23" {
+		t.Errorf("HEAD Content-Length expect 23, actual %q", cl)
+	}
+}
+
+func TestStaticFSDirectory(t *testing.T) {
+	app := testStaticApp()
+
+	rec := testServe(app, httptest.NewRequest(http.MethodGet, "/assets/docs", nil))
+	if rec.Code != http.StatusMovedPermanently || rec.Header().Get("Location") != "docs/" {
+		t.Errorf("Directory expect redirect to \"docs/\", actual %d %q", rec.Code, rec.Header().Get("Location"))
+	}
+
+	rec = testServe(app, httptest.NewRequest(http.MethodGet, "/assets/docs/", nil))
+	if rec.Body.String() != "<h1>Docs</h1>" {
+		t.Errorf("Directory expect index file, actual %q", rec.Body.String())
+	}
+
+	rec = testServe(app, httptest.NewRequest(http.MethodGet, "/assets/images/", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Directory without index expect 404, actual %d", rec.Code)
+	}
+
+	app = testStaticApp(StaticConfig{Browse: true})
+	rec = testServe(app, httptest.NewRequest(http.MethodGet, "/assets/images/", nil))
+	body := rec.Body.String()
+	if !strings.Contains(body, `<a href="other.txt">other.txt</a>`) ||
+		!strings.Contains(body, "&lt;a&gt;.txt") {
+		t.Errorf("Directory listing expect escaped entries, actual %q", body)
+	}
+}
+
+func TestStaticFSPathTraversal(t *testing.T) {
+	app := testStaticApp()
+
+	for _, p := range []string{"/assets/../static_test.go", "/assets/..%2fstatic_test.go", "/assets/missing.js"} {
+		rec := testServe(app, httptest.NewRequest(http.MethodGet, p, nil))
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("Request %s expect 404, actual %d", p, rec.Code)
+		}
+	}
+}
+
+func TestStaticFSWithoutNotFoundHandler(t *testing.T) {
+	router := NewRouter()
+	router.NotFoundHandler = nil
+	router.StaticFS("/assets", fstest.MapFS{})
+
+	app := New(nil)
+	app.Use(router.Routes())
+
+	rec := testServe(app, httptest.NewRequest(http.MethodGet, "/assets/missing.js", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Missing file expect 404, actual %d", rec.Code)
+	}
+}
+
+func TestContextAttachment(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "report.csv")
+	if err := os.WriteFile(file, []byte("a,b\n1,2\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	rec := testRequest(func(c *Context) {
+		if err := c.Attachment(file, "报告.csv"); err != nil {
+			t.Errorf("Attachment expect no error, actual %v", err)
+		}
+	}, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if rec.Body.String() != "a,b\n1,2\n" {
+		t.Errorf("Attachment body expect file content, actual %q", rec.Body.String())
+	}
+	if cd := rec.Header().Get("Content-Disposition"); !strings.HasPrefix(cd, "attachment; filename*=utf-8''") {
+		t.Errorf("Content-Disposition expect encoded file name, actual %q", cd)
+	}
+
+	testRequest(func(c *Context) {
+		if err := c.SendFile(dir + "/../" + filepath.Base(dir) + "/report.csv"); err != ErrInvalidFilePath {
+			t.Errorf("SendFile with \"..\" expect ErrInvalidFilePath, actual %v", err)
+		}
+	}, httptest.NewRequest(http.MethodGet, "/", nil))
+}
+
+func TestRouterSPA(t *testing.T) {
+	fsys := fstest.MapFS{
+		"index.html":               {Data: []byte("<div id=\"app\"></div>")},
+		"favicon.ico":              {Data: []byte("icon")},
+		"assets/index-4f3a9b2c.js": {Data: []byte("app")},
+	}
+
+	router := NewRouter()
+	router.GET("/api/users", func(c *Context) {
+		c.JSON(O{"users": []string{}})
+	})
+	router.SPA(fsys, SPAConfig{ExcludePrefixes: []string{"/api/"}})
+
+	app := New(nil)
+	app.Use(router.Routes())
+
+	cases := []struct {
+		method       string
+		path         string
+		accept       string
+		code         int
+		body         string
+		cacheControl string
+	}{
+		{http.MethodGet, "/api/users", "", http.StatusOK, `{"users":[]}`, ""},
+		{http.MethodGet, "/", "", http.StatusOK, "<div id=\"app\"></div>", "no-cache"},
+		{http.MethodGet, "/settings/profile", "text/html,*/*;q=0.8", http.StatusOK, "<div id=\"app\"></div>", "no-cache"},
+		{http.MethodGet, "/settings/profile", "application/json", http.StatusNotFound, "Not Found", ""},
+		{http.MethodGet, "/api/unknown", "text/html", http.StatusNotFound, "Not Found", ""},
+		{http.MethodPost, "/settings", "text/html", http.StatusNotFound, "Not Found", ""},
+		{http.MethodGet, "/favicon.ico", "", http.StatusOK, "icon", "no-cache"},
+		{http.MethodGet, "/assets/index-4f3a9b2c.js", "", http.StatusOK, "app", "public, max-age=31536000, immutable"},
+	}
+
+	for _, c := range cases {
+		req := httptest.NewRequest(c.method, c.path, nil)
+		if c.accept != "" {
+			req.Header.Set("Accept", c.accept)
+		}
+		rec := testServe(app, req)
+
+		if rec.Code != c.code || rec.Body.String() != c.body {
+			t.Errorf("%s %s expect %d %q, actual %d %q", c.method, c.path, c.code, c.body, rec.Code, rec.Body.String())
+		}
+		if cc := rec.Header().Get("Cache-Control"); cc != c.cacheControl {
+			t.Errorf("%s %s Cache-Control expect %q, actual %q", c.method, c.path, c.cacheControl, cc)
+		}
+	}
+}
+
+func TestIsHashedAsset(t *testing.T) {
+	cases := map[string]bool{
+		"assets/index-BPvgi06w.js":   true,
+		"static/js/main.3f2a1b9c.js": true,
+		"favicon.ico":                false,
+		"index-component.js":         false,
+		"app.js":                     false,
+		"3f2a1b9c":                   false,
+	}
+
+	for name, expected := range cases {
+		if actual := isHashedAsset(name); actual != expected {
+			t.Errorf("isHashedAsset(%q) expect %v, actual %v", name, expected, actual)
+		}
+	}
+}
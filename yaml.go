@@ -0,0 +1,421 @@
+package dolphin
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/base64"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// yamlIndent is the indentation of the nested YAML nodes.
+const yamlIndent = "  "
+
+// marshalYAML encodes the given value to a YAML document. It's a minimal
+// encoder that only supports the block style of maps, sequences, and scalars.
+//
+// Struct fields are encoded with the name in the "yaml" tag, or the lower
+// case field name if the tag is not set. The tag options "omitempty",
+// "inline", and "-" are also supported. The fields of the exported embedded
+// structs without the tag name are inlined like the "inline" option, and the fields
+// of the outer struct take precedence over the inlined fields with the same
+// name. Byte slices are encoded as the base64 "!!binary" scalars.
+func marshalYAML(data any) ([]byte, error) {
+	buf := new(bytes.Buffer)
+
+	if err := encodeYAMLNode(buf, reflect.ValueOf(data), 0); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// encodeYAMLNode writes the given value to the buffer as a YAML node at the
+// specific indentation level.
+func encodeYAMLNode(buf *bytes.Buffer, val reflect.Value, level int) error {
+	val = indirectYAMLValue(val)
+
+	if isYAMLScalar(val) {
+		scalar, err := encodeYAMLScalar(val)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(scalar)
+		buf.WriteByte('\n')
+		return nil
+	}
+
+	switch val.Kind() {
+	case reflect.Map:
+		return encodeYAMLMap(buf, val, level)
+	case reflect.Struct:
+		return encodeYAMLStruct(buf, val, level)
+	case reflect.Slice, reflect.Array:
+		return encodeYAMLSequence(buf, val, level)
+	default:
+		return fmt.Errorf("yaml: unsupported type %s", val.Type())
+	}
+}
+
+// encodeYAMLMap writes the given map to the buffer, the keys are sorted to
+// make the output stable.
+func encodeYAMLMap(buf *bytes.Buffer, val reflect.Value, level int) error {
+	if val.Len() == 0 {
+		buf.WriteString("{}\n")
+		return nil
+	}
+
+	keys := make([]string, 0, val.Len())
+	values := make(map[string]reflect.Value, val.Len())
+	for iter := val.MapRange(); iter.Next(); {
+		key, err := encodeYAMLScalar(indirectYAMLValue(iter.Key()))
+		if err != nil {
+			return err
+		}
+		keys = append(keys, key)
+		values[key] = iter.Value()
+	}
+	sort.Strings(keys)
+
+	for i, key := range keys {
+		if err := encodeYAMLPair(buf, key, values[key], level, i == 0); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// yamlField is a field of the struct that to be encoded.
+type yamlField struct {
+	name  string
+	val   reflect.Value
+	depth int
+}
+
+// encodeYAMLStruct writes the exported fields of the given struct to the
+// buffer as a YAML map.
+func encodeYAMLStruct(buf *bytes.Buffer, val reflect.Value, level int) error {
+	fields := collectYAMLFields(val, 0, nil)
+	written := 0
+
+	for _, field := range fields {
+		key, err := encodeYAMLScalar(reflect.ValueOf(field.name))
+		if err != nil {
+			return err
+		}
+
+		if err := encodeYAMLPair(buf, key, field.val, level, written == 0); err != nil {
+			return err
+		}
+		written++
+	}
+
+	if written == 0 {
+		buf.WriteString("{}\n")
+	}
+
+	return nil
+}
+
+// collectYAMLFields appends the fields of the struct to encode to the list, the fields of the
+// inlined structs are collected recursively. The field with the same name of a shallower
+// depth, or the first one of the same depth, is kept.
+func collectYAMLFields(val reflect.Value, depth int, fields []yamlField) []yamlField {
+	typ := val.Type()
+
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, omitEmpty, inline, skip := parseYAMLTag(field)
+		if skip {
+			continue
+		}
+
+		fieldVal := val.Field(i)
+		if inline || (field.Anonymous && name == "") {
+			if embedded := indirectYAMLValue(fieldVal); isInlineYAMLStruct(embedded) {
+				fields = collectYAMLFields(embedded, depth+1, fields)
+				continue
+			} else if !embedded.IsValid() {
+				// The nil embedded struct pointer has no fields.
+				continue
+			}
+		}
+
+		if omitEmpty && fieldVal.IsZero() {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+
+		fields = addYAMLField(fields, yamlField{name: name, val: fieldVal, depth: depth})
+	}
+
+	return fields
+}
+
+// addYAMLField appends the field to the list, or replaces the field with the same name if the
+// new one is shallower.
+func addYAMLField(fields []yamlField, field yamlField) []yamlField {
+	for i, f := range fields {
+		if f.name != field.name {
+			continue
+		}
+
+		if field.depth < f.depth {
+			fields = append(fields[:i], fields[i+1:]...)
+			break
+		}
+		return fields
+	}
+
+	return append(fields, field)
+}
+
+// isInlineYAMLStruct reports whether the value is a struct that its fields can be inlined.
+func isInlineYAMLStruct(val reflect.Value) bool {
+	return val.IsValid() && val.Kind() == reflect.Struct && !isYAMLScalar(val)
+}
+
+// encodeYAMLPair writes a key-value pair of a map to the buffer. The first
+// pair is written without indentation because it may follow a sequence
+// indicator.
+func encodeYAMLPair(buf *bytes.Buffer, key string, val reflect.Value, level int, first bool) error {
+	if !first {
+		buf.WriteString(strings.Repeat(yamlIndent, level))
+	}
+	buf.WriteString(key)
+	buf.WriteByte(':')
+
+	val = indirectYAMLValue(val)
+	if isYAMLScalar(val) || isEmptyYAMLCollection(val) {
+		buf.WriteByte(' ')
+		return encodeYAMLNode(buf, val, level+1)
+	}
+
+	buf.WriteByte('\n')
+	if val.Kind() == reflect.Slice || val.Kind() == reflect.Array {
+		buf.WriteString(strings.Repeat(yamlIndent, level))
+		return encodeYAMLNode(buf, val, level)
+	}
+
+	buf.WriteString(strings.Repeat(yamlIndent, level+1))
+	return encodeYAMLNode(buf, val, level+1)
+}
+
+// encodeYAMLSequence writes the given slice or array to the buffer.
+func encodeYAMLSequence(buf *bytes.Buffer, val reflect.Value, level int) error {
+	if val.Len() == 0 {
+		buf.WriteString("[]\n")
+		return nil
+	}
+
+	for i := 0; i < val.Len(); i++ {
+		if i > 0 {
+			buf.WriteString(strings.Repeat(yamlIndent, level))
+		}
+		buf.WriteString("- ")
+
+		if err := encodeYAMLNode(buf, val.Index(i), level+1); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// encodeYAMLScalar returns the YAML representation of the given scalar value.
+func encodeYAMLScalar(val reflect.Value) (string, error) {
+	if !val.IsValid() {
+		return "null", nil
+	}
+
+	if val.Type() == reflect.TypeOf(time.Time{}) {
+		return val.Interface().(time.Time).Format(time.RFC3339Nano), nil
+	}
+
+	if marshaler, ok := val.Interface().(encoding.TextMarshaler); ok {
+		text, err := marshaler.MarshalText()
+		if err != nil {
+			return "", err
+		}
+		return quoteYAMLString(string(text)), nil
+	}
+
+	switch val.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+		if val.IsNil() {
+			return "null", nil
+		}
+		if isYAMLBinary(val) {
+			return "!!binary " + quoteYAMLString(base64.StdEncoding.EncodeToString(val.Bytes())), nil
+		}
+	case reflect.Bool:
+		return strconv.FormatBool(val.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(val.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(val.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return formatYAMLFloat(val.Float(), val.Type().Bits()), nil
+	case reflect.String:
+		return quoteYAMLString(val.String()), nil
+	}
+
+	return "", fmt.Errorf("yaml: unsupported type %s", val.Type())
+}
+
+// formatYAMLFloat returns the YAML representation of the given float number.
+func formatYAMLFloat(f float64, bitSize int) string {
+	switch {
+	case math.IsNaN(f):
+		return ".nan"
+	case math.IsInf(f, 1):
+		return ".inf"
+	case math.IsInf(f, -1):
+		return "-.inf"
+	}
+
+	return strconv.FormatFloat(f, 'g', -1, bitSize)
+}
+
+// quoteYAMLString returns the string as a plain scalar if it can't be
+// confused with other types or YAML syntax, otherwise returns it as a double
+// quoted scalar.
+func quoteYAMLString(s string) string {
+	if needsYAMLQuote(s) {
+		return strconv.Quote(s)
+	}
+
+	return s
+}
+
+// needsYAMLQuote reports whether the string needs to be quoted.
+func needsYAMLQuote(s string) bool {
+	if s == "" || strings.TrimSpace(s) != s {
+		return true
+	}
+
+	switch strings.ToLower(s) {
+	case "~", "null", "true", "false", "yes", "no", "on", "off", "y", "n",
+		".inf", "-.inf", "+.inf", ".nan":
+		return true
+	}
+
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return true
+	}
+
+	if strings.ContainsAny(s[:1], "-?:,[]{}#&*!|>'\"%@`") {
+		return true
+	}
+
+	if strings.Contains(s, ": ") || strings.Contains(s, " #") {
+		return true
+	}
+
+	for _, r := range s {
+		if r < 0x20 || r == 0x7f || r == '\u2028' || r == '\u2029' {
+			return true
+		}
+	}
+
+	return strings.HasSuffix(s, ":")
+}
+
+// parseYAMLTag returns the field name and options from the "yaml" tag of the
+// struct field, the name is empty if it's not set by the tag.
+func parseYAMLTag(field reflect.StructField) (name string, omitEmpty, inline, skip bool) {
+	tag := field.Tag.Get("yaml")
+	if tag == "-" {
+		return "", false, false, true
+	}
+
+	parts := strings.Split(tag, ",")
+	name = parts[0]
+
+	for _, opt := range parts[1:] {
+		switch opt {
+		case "omitempty":
+			omitEmpty = true
+		case "inline":
+			inline = true
+		}
+	}
+
+	return name, omitEmpty, inline, false
+}
+
+// indirectYAMLValue dereferences pointers and interfaces until it gets a
+// concrete value or a nil value.
+func indirectYAMLValue(val reflect.Value) reflect.Value {
+	for val.IsValid() && (val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface) {
+		if val.IsNil() {
+			return reflect.Value{}
+		}
+
+		if _, ok := val.Interface().(encoding.TextMarshaler); ok && val.Kind() == reflect.Ptr {
+			return val
+		}
+
+		val = val.Elem()
+	}
+
+	return val
+}
+
+// isYAMLScalar reports whether the value should be encoded as a scalar.
+func isYAMLScalar(val reflect.Value) bool {
+	if !val.IsValid() {
+		return true
+	}
+
+	if val.Type() == reflect.TypeOf(time.Time{}) {
+		return true
+	}
+
+	if val.CanInterface() {
+		if _, ok := val.Interface().(encoding.TextMarshaler); ok {
+			return true
+		}
+	}
+
+	switch val.Kind() {
+	case reflect.Map, reflect.Struct, reflect.Array:
+		return false
+	case reflect.Slice:
+		return val.IsNil() || isYAMLBinary(val)
+	}
+
+	return true
+}
+
+// isYAMLBinary reports whether the value is a byte slice, it's encoded as a binary scalar.
+func isYAMLBinary(val reflect.Value) bool {
+	return val.Kind() == reflect.Slice && val.Type().Elem().Kind() == reflect.Uint8
+}
+
+// isEmptyYAMLCollection reports whether the value is an empty map, slice, or
+// array, they're written in the flow style.
+func isEmptyYAMLCollection(val reflect.Value) bool {
+	if isYAMLBinary(val) {
+		return false
+	}
+
+	switch val.Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array:
+		return val.Len() == 0
+	}
+
+	return false
+}
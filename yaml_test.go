@@ -0,0 +1,76 @@
+package dolphin
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMarshalYAML(t *testing.T) {
+	type item struct {
+		ID      int      `yaml:"id"`
+		Tags    []string `yaml:"tags"`
+		Note    string   `yaml:"note,omitempty"`
+		Ignored string   `yaml:"-"`
+	}
+	type Base struct {
+		ID      int
+		Created string
+	}
+	type Meta struct {
+		Labels []string
+	}
+	type resource struct {
+		Base
+		*Meta
+		ID    string
+		Extra Meta `yaml:",inline"`
+		Named Base `yaml:"named"`
+	}
+
+	cases := []struct {
+		data     any
+		expected string
+	}{
+		{nil, "null\n"},
+		{"hello", "hello\n"},
+		{"true", "\"true\"\n"},
+		{"123", "\"123\"\n"},
+		{"a: b", "\"a: b\"\n"},
+		{"", "\"\"\n"},
+		{42, "42\n"},
+		{1.5, "1.5\n"},
+		{math.Inf(1), ".inf\n"},
+		{[]int{}, "[]\n"},
+		{O{}, "{}\n"},
+		{[]string{"a", "b"}, "- a\n- b\n"},
+		{O{"b": 1, "a": "x"}, "a: x\nb: 1\n"},
+		{
+			O{"items": []item{{ID: 1, Tags: []string{"x"}}, {ID: 2, Tags: nil, Note: "memo"}}},
+			"items:\n- id: 1\n  tags:\n  - x\n- id: 2\n  tags: null\n  note: memo\n",
+		},
+		{O{"nested": O{"key": []int{}}}, "nested:\n  key: []\n"},
+		{[][]int{{1, 2}, {3}}, "- - 1\n  - 2\n- - 3\n"},
+		{[]byte("hello"), "!!binary aGVsbG8=\n"},
+		{O{"data": []byte{}, "nil": []byte(nil)}, "data: !!binary \"\"\nnil: null\n"},
+		{
+			resource{Base: Base{ID: 1, Created: "today"}, ID: "r1", Extra: Meta{Labels: []string{"a"}}},
+			"created: today\nid: r1\nlabels:\n- a\nnamed:\n  id: 0\n  created: \"\"\n",
+		},
+	}
+
+	for _, c := range cases {
+		out, err := marshalYAML(c.data)
+		if err != nil {
+			t.Errorf("marshalYAML(%v) expect no error, actual %v", c.data, err)
+			continue
+		}
+
+		if string(out) != c.expected {
+			t.Errorf("marshalYAML(%v) expect %q, actual %q", c.data, c.expected, string(out))
+		}
+	}
+
+	if _, err := marshalYAML(make(chan int)); err == nil {
+		t.Errorf("marshalYAML(chan) expect error, actual nil")
+	}
+}